@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/QuantumNous/new-api/logger"
+	"github.com/QuantumNous/new-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// ContextKeyRelayFormat is the gin context key holding the types.RelayFormat of the request.
+// ErrorHandler renders errors in the OpenAI format when it is not set.
+const ContextKeyRelayFormat = "relay_format"
+
+// SetRelayFormat records the relay format of the request for ErrorHandler
+func SetRelayFormat(c *gin.Context, format types.RelayFormat) {
+	c.Set(ContextKeyRelayFormat, format)
+}
+
+// PanicError is the cause of the error ErrorHandler reports for a recovered panic
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// ErrorHandler renders the error a handler attached with c.Error in the relay format of the
+// request and logs it at its level. Plain errors are wrapped in ErrorCodeInternalError so
+// their text does not reach the client, and panics are recovered as critical internal errors.
+// Errors raised after a streaming response started are written as in-stream error events.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				if r == http.ErrAbortHandler {
+					// the handler deliberately aborted the response
+					panic(r)
+				}
+				cause := &PanicError{Value: r, Stack: debug.Stack()}
+				handleError(c, types.NewError(cause, types.ErrorCodeInternalError, types.ErrOptionWithLevel(types.ErrorLevelCritical)))
+			}
+		}()
+
+		c.Next()
+
+		if len(c.Errors) > 0 {
+			handleError(c, toNewAPIError(c.Errors.Last().Err))
+		}
+	}
+}
+
+// toNewAPIError returns the NewAPIError in the chain of err, or wraps err in an internal error
+func toNewAPIError(err error) *types.NewAPIError {
+	var newAPIError *types.NewAPIError
+	if errors.As(err, &newAPIError) && newAPIError != nil {
+		return newAPIError
+	}
+	return types.NewError(err, types.ErrorCodeInternalError)
+}
+
+// handleError logs the error and renders it. Once the response has started, only event
+// streams can still carry the error, as an in-stream event.
+func handleError(c *gin.Context, err *types.NewAPIError) {
+	if types.IsRecordErrorLog(err) {
+		logNewAPIError(c, err)
+	}
+	value, _ := c.Get(ContextKeyRelayFormat)
+	format, ok := value.(types.RelayFormat)
+	if !ok {
+		format = types.RelayFormatOpenAI
+	}
+	if !c.Writer.Written() {
+		AbortWithError(c, format, err)
+		return
+	}
+	if strings.HasPrefix(c.Writer.Header().Get("Content-Type"), "text/event-stream") {
+		AbortWithStreamError(c, format, err)
+	}
+}
+
+// logNewAPIError writes the error to the log at its level
+func logNewAPIError(c *gin.Context, err *types.NewAPIError) {
+	ctx := c.Request.Context()
+	msg := fmt.Sprintf("%s %s: status %d, code %d (%s): %s",
+		c.Request.Method, c.Request.URL.Path, err.StatusCode, int(err.GetErrorCode()), err.GetErrorCode().String(), err.MaskSensitiveError())
+	if progress, ok := err.StreamProgress(); ok {
+		msg += fmt.Sprintf(" (after %d chunks, %d tokens)", progress.Chunks, progress.Tokens)
+	}
+	var panicErr *PanicError
+	if errors.As(err, &panicErr) {
+		msg += "\n" + string(panicErr.Stack)
+	}
+	switch err.Level {
+	case types.ErrorLevelInfo:
+		logger.LogInfo(ctx, msg)
+	case types.ErrorLevelWarning:
+		logger.LogWarn(ctx, msg)
+	default:
+		logger.LogError(ctx, msg)
+	}
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/QuantumNous/new-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// TestErrorHandler verifies attached errors and panics are rendered in the relay format
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		format         types.RelayFormat
+		handler        gin.HandlerFunc
+		expectedStatus int
+		expectedCode   string
+		expectedBody   string
+		unexpectedBody string
+	}{
+		{
+			name:   "NewAPIError in Claude format",
+			format: types.RelayFormatClaude,
+			handler: func(c *gin.Context) {
+				_ = c.Error(types.NewError(errors.New("no quota"), types.ErrorCodeInsufficientUserQuota))
+			},
+			expectedStatus: http.StatusPaymentRequired,
+			expectedCode:   "7001",
+			expectedBody:   `"type":"error"`,
+		},
+		{
+			name: "Wrapped NewAPIError",
+			handler: func(c *gin.Context) {
+				err := types.NewError(errors.New("token expired"), types.ErrorCodeTokenExpired)
+				_ = c.Error(errors.Join(errors.New("auth"), err))
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "8001",
+			expectedBody:   `"code":"token_expired"`,
+		},
+		{
+			name: "Plain error is not leaked",
+			handler: func(c *gin.Context) {
+				_ = c.Error(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "2000",
+			expectedBody:   `"message":"Internal server error"`,
+			unexpectedBody: "10.0.0.5",
+		},
+		{
+			name:   "Panic",
+			format: types.RelayFormatGemini,
+			handler: func(c *gin.Context) {
+				var m map[string]int
+				m["boom"] = 1
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedCode:   "2000",
+			expectedBody:   `"status":"INTERNAL"`,
+			unexpectedBody: "nil map",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(ErrorHandler())
+			router.GET("/", func(c *gin.Context) {
+				if tt.format != "" {
+					SetRelayFormat(c, tt.format)
+				}
+				c.Next()
+			}, tt.handler)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if recorder.Code != tt.expectedStatus {
+				t.Errorf("ErrorHandler() status = %d, want %d", recorder.Code, tt.expectedStatus)
+			}
+			if got := recorder.Header().Get(types.HeaderErrorCode); got != tt.expectedCode {
+				t.Errorf("ErrorHandler() %s = %q, want %q", types.HeaderErrorCode, got, tt.expectedCode)
+			}
+			body := recorder.Body.String()
+			if !strings.Contains(body, tt.expectedBody) {
+				t.Errorf("ErrorHandler() body = %s, want it to contain %s", body, tt.expectedBody)
+			}
+			if tt.unexpectedBody != "" && strings.Contains(body, tt.unexpectedBody) {
+				t.Errorf("ErrorHandler() body = %s, must not contain %s", body, tt.unexpectedBody)
+			}
+		})
+	}
+}
+
+// TestErrorHandlerPanicLevel verifies a recovered panic is critical and keeps the stack
+func TestErrorHandlerPanicLevel(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if got := recorder.Header().Get(types.HeaderErrorLevel); got != types.ErrorLevelCritical.String() {
+		t.Errorf("ErrorHandler() %s = %q, want %q", types.HeaderErrorLevel, got, types.ErrorLevelCritical.String())
+	}
+
+	err := toNewAPIError(&PanicError{Value: "boom", Stack: []byte("goroutine 1")})
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || string(panicErr.Stack) != "goroutine 1" {
+		t.Errorf("toNewAPIError() lost the panic stack")
+	}
+}
+
+// TestErrorHandlerWrittenResponse verifies a response already started by the handler is left alone
+func TestErrorHandlerWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, "partial")
+		_ = c.Error(types.NewError(errors.New("stream broke"), types.ErrorCodeBadResponse))
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if recorder.Code != http.StatusOK || recorder.Body.String() != "partial" {
+		t.Errorf("ErrorHandler() rewrote a started response: %d %s", recorder.Code, recorder.Body.String())
+	}
+}
+
+// TestErrorHandlerStream verifies errors after the first chunk become in-stream error events
+func TestErrorHandlerStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.GET("/", func(c *gin.Context) {
+		SetRelayFormat(c, types.RelayFormatClaude)
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "event: ping\ndata: {}\n\n")
+		err := types.NewError(errors.New("stream broke"), types.ErrorCodeBadResponse,
+			types.ErrOptionWithStreamProgress(types.StreamProgress{Chunks: 1}))
+		_ = c.Error(err)
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if recorder.Code != http.StatusOK {
+		t.Errorf("ErrorHandler() status = %d, want %d", recorder.Code, http.StatusOK)
+	}
+	body := recorder.Body.String()
+	if !strings.HasPrefix(body, "event: ping\n") || !strings.Contains(body, "event: error\ndata: {\"type\":\"error\"") {
+		t.Errorf("ErrorHandler() body = %q, want the stream followed by an error event", body)
+	}
+}
+
+// TestErrorHandlerResponsesStream verifies a Responses API stream that failed after
+// response.created ends with response.failed, numbered after the events already sent
+func TestErrorHandlerResponsesStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler())
+	router.POST("/v1/responses", func(c *gin.Context) {
+		SetRelayFormat(c, types.RelayFormatOpenAIResponses)
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "event: response.created\ndata: {\"sequence_number\":0}\n\n")
+		SetResponsesStreamState(c, "resp_123", 1)
+		_ = c.Error(types.NewError(errors.New("stream broke"), types.ErrorCodeBadResponse))
+	})
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/responses", nil))
+
+	body := recorder.Body.String()
+	if !strings.Contains(body, "event: response.failed\ndata: {\"type\":\"response.failed\",\"sequence_number\":1,\"response\":{\"id\":\"resp_123\"") {
+		t.Errorf("ErrorHandler() body = %q, want response.failed for resp_123 numbered 1", body)
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"github.com/QuantumNous/new-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// Gin context keys holding the state of a Responses API stream, so in-stream errors can fail
+// the response and continue its event numbering
+const (
+	ContextKeyResponseID              = "response_id"
+	ContextKeyResponsesSequenceNumber = "responses_sequence_number"
+)
+
+// SetResponsesStreamState records the id of the Responses API response being streamed and the
+// sequence number of the next event. Relays update it as they forward events; the id stays
+// empty until response.created was sent.
+func SetResponsesStreamState(c *gin.Context, responseID string, nextSequenceNumber int) {
+	c.Set(ContextKeyResponseID, responseID)
+	c.Set(ContextKeyResponsesSequenceNumber, nextSequenceNumber)
+}
+
+// AbortWithError renders err in the envelope of the relay format and aborts the handler chain.
+// The message language is taken from the Accept-Language header of the request.
+func AbortWithError(c *gin.Context, format types.RelayFormat, err *types.NewAPIError) {
+	lang := types.GetLanguageFromContext(c.GetHeader("Accept-Language"))
+	err.Render(c.Writer, format, lang)
+	c.Abort()
+}
+
+// AbortWithStreamError writes err as an in-stream error event of the relay format to a
+// streaming response that has already started, and aborts the handler chain. Responses API
+// streams get response.failed or an error event numbered from SetResponsesStreamState.
+func AbortWithStreamError(c *gin.Context, format types.RelayFormat, err *types.NewAPIError) {
+	lang := types.GetLanguageFromContext(c.GetHeader("Accept-Language"))
+	if format == types.RelayFormatOpenAIResponses {
+		_ = err.RenderResponsesStream(c.Writer, c.GetString(ContextKeyResponseID), c.GetInt(ContextKeyResponsesSequenceNumber), lang)
+	} else {
+		_ = err.RenderStream(c.Writer, format, lang)
+	}
+	c.Abort()
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/QuantumNous/new-api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// TestAbortWithError verifies the gin adapter renders the error and stops the chain
+func TestAbortWithError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	reached := false
+	router.POST("/v1/messages", func(c *gin.Context) {
+		AbortWithError(c, types.RelayFormatClaude, types.NewError(errors.New("no quota"), types.ErrorCodeInsufficientUserQuota))
+	}, func(c *gin.Context) {
+		reached = true
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/v1/messages", nil)
+	request.Header.Set("Accept-Language", "zh-CN")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if reached {
+		t.Errorf("AbortWithError() did not abort the handler chain")
+	}
+	if recorder.Code != http.StatusPaymentRequired {
+		t.Errorf("AbortWithError() status = %d, want %d", recorder.Code, http.StatusPaymentRequired)
+	}
+	if got := recorder.Header().Get(types.HeaderErrorCode); got != "7001" {
+		t.Errorf("AbortWithError() %s = %q, want %q", types.HeaderErrorCode, got, "7001")
+	}
+	if body := recorder.Body.String(); !strings.Contains(body, `"type":"error"`) || !strings.Contains(body, `"billing_error"`) {
+		t.Errorf("AbortWithError() body = %s, want a Claude error envelope", body)
+	}
+}
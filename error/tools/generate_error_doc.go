@@ -8,97 +8,75 @@ import (
 	"os"
 	"sort"
 	"text/template"
+
+	"github.com/QuantumNous/new-api/types"
 )
 
 // ErrorDoc represents documentation for a single error code
 type ErrorDoc struct {
-	Code       int
-	Name       string
-	Category   string
-	HTTPStatus int
-	Level      string
+	Code        int
+	Name        string
+	Category    string
+	HTTPStatus  int
+	Level       string
+	Retry       string
 	Description string
 }
 
-// Category groups error codes by their numeric range
-func getCategory(code int) string {
-	switch {
-	case code >= 1000 && code < 2000:
-		return "General Errors (1xxx)"
-	case code >= 2000 && code < 3000:
-		return "System Errors (2xxx)"
-	case code >= 3000 && code < 4000:
-		return "Channel Errors (3xxx)"
-	case code >= 4000 && code < 5000:
-		return "Client Errors (4xxx)"
-	case code >= 5000 && code < 6000:
-		return "Upstream Errors (5xxx)"
-	case code >= 6000 && code < 7000:
-		return "Database Errors (6xxx)"
-	case code >= 7000 && code < 8000:
-		return "Quota Errors (7xxx)"
-	default:
-		return "Other"
+// getCategory returns the section title of a category, e.g. "Upstream Errors (5xxx)"
+func getCategory(category types.ErrorCategory) string {
+	return fmt.Sprintf("%s (%s)", category.LocalizedName("en"), category.RangeLabel())
+}
+
+// CategoryDoc represents documentation for an error category and its policy
+type CategoryDoc struct {
+	Name            string
+	Range           string
+	ExposeMessage   bool
+	Level           string
+	Retry           string
+	PenalizeChannel bool
+}
+
+// collectCategoryDocs builds the category table from the category definitions
+func collectCategoryDocs() []CategoryDoc {
+	var docs []CategoryDoc
+	for _, info := range types.ListCategories() {
+		docs = append(docs, CategoryDoc{
+			Name:            info.Category.LocalizedName("en"),
+			Range:           info.Category.RangeLabel(),
+			ExposeMessage:   info.Policy.ExposeMessage,
+			Level:           info.Policy.Level.String(),
+			Retry:           info.Policy.Retry.String(),
+			PenalizeChannel: info.Policy.PenalizeChannel,
+		})
 	}
+	return docs
 }
 
-// Error code definitions (must match types/error_code.go)
-var errorCodes = []ErrorDoc{
-	// General Errors (1xxx)
-	{1001, "ErrorCodeInvalidRequest", "General Errors (1xxx)", 400, "warning", "Invalid request parameters"},
-	{1002, "ErrorCodeSensitiveWordsDetected", "General Errors (1xxx)", 400, "warning", "Sensitive words detected in content"},
-
-	// System Errors (2xxx)
-	{2001, "ErrorCodeCountTokenFailed", "System Errors (2xxx)", 500, "error", "Failed to count tokens"},
-	{2002, "ErrorCodeModelPriceError", "System Errors (2xxx)", 500, "error", "Model pricing configuration error"},
-	{2003, "ErrorCodeInvalidApiType", "System Errors (2xxx)", 400, "error", "Invalid API type"},
-	{2004, "ErrorCodeJsonMarshalFailed", "System Errors (2xxx)", 500, "error", "Failed to marshal JSON"},
-	{2005, "ErrorCodeJsonUnmarshalFailed", "System Errors (2xxx)", 500, "error", "Failed to unmarshal JSON"},
-	{2006, "ErrorCodeDoRequestFailed", "System Errors (2xxx)", 500, "error", "Failed to make HTTP request"},
-	{2007, "ErrorCodeGetChannelFailed", "System Errors (2xxx)", 500, "critical", "Failed to get channel information"},
-	{2008, "ErrorCodeGenRelayInfoFailed", "System Errors (2xxx)", 500, "error", "Failed to generate relay information"},
-
-	// Channel Errors (3xxx)
-	{3001, "ErrorCodeChannelNoAvailableKey", "Channel Errors (3xxx)", 503, "error", "No available API key in channel"},
-	{3002, "ErrorCodeChannelParamOverrideInvalid", "Channel Errors (3xxx)", 400, "warning", "Invalid channel parameter override"},
-	{3003, "ErrorCodeChannelHeaderOverrideInvalid", "Channel Errors (3xxx)", 400, "warning", "Invalid channel header override"},
-	{3004, "ErrorCodeChannelModelMappedError", "Channel Errors (3xxx)", 500, "error", "Channel model mapping error"},
-	{3005, "ErrorCodeChannelAwsClientError", "Channel Errors (3xxx)", 500, "error", "AWS client configuration error"},
-	{3006, "ErrorCodeChannelInvalidKey", "Channel Errors (3xxx)", 401, "warning", "Invalid channel API key"},
-	{3007, "ErrorCodeChannelResponseTimeExceeded", "Channel Errors (3xxx)", 504, "warning", "Channel response time exceeded"},
-	{3008, "ErrorCodeChannelNotAvailable", "Channel Errors (3xxx)", 503, "critical", "Channel is not available"},
-
-	// Client Errors (4xxx)
-	{4001, "ErrorCodeReadRequestBodyFailed", "Client Errors (4xxx)", 400, "warning", "Failed to read request body"},
-	{4002, "ErrorCodeConvertRequestFailed", "Client Errors (4xxx)", 400, "warning", "Failed to convert request format"},
-	{4003, "ErrorCodeAccessDenied", "Client Errors (4xxx)", 401, "warning", "Access denied"},
-	{4004, "ErrorCodeBadRequestBody", "Client Errors (4xxx)", 400, "warning", "Invalid request body"},
-	{4005, "ErrorCodeUnauthorized", "Client Errors (4xxx)", 401, "warning", "Unauthorized access"},
-	{4006, "ErrorCodeForbidden", "Client Errors (4xxx)", 403, "warning", "Forbidden"},
-
-	// Upstream Errors (5xxx)
-	{5001, "ErrorCodeReadResponseBodyFailed", "Upstream Errors (5xxx)", 500, "error", "Failed to read response body"},
-	{5002, "ErrorCodeBadResponseStatusCode", "Upstream Errors (5xxx)", 502, "error", "Bad response status code from upstream"},
-	{5003, "ErrorCodeBadResponse", "Upstream Errors (5xxx)", 502, "error", "Bad response from upstream service"},
-	{5004, "ErrorCodeBadResponseBody", "Upstream Errors (5xxx)", 500, "error", "Invalid response body format"},
-	{5005, "ErrorCodeEmptyResponse", "Upstream Errors (5xxx)", 500, "error", "Empty response from upstream"},
-	{5006, "ErrorCodeAwsInvokeError", "Upstream Errors (5xxx)", 500, "error", "AWS invocation error"},
-	{5007, "ErrorCodeModelNotFound", "Upstream Errors (5xxx)", 404, "warning", "Model not found"},
-	{5008, "ErrorCodePromptBlocked", "Upstream Errors (5xxx)", 400, "warning", "Prompt blocked by content filter"},
-	{5009, "ErrorCodeRateLimitExceeded", "Upstream Errors (5xxx)", 429, "warning", "Rate limit exceeded"},
-	{5010, "ErrorCodeServiceUnavailable", "Upstream Errors (5xxx)", 503, "critical", "Service temporarily unavailable"},
-
-	// Database Errors (6xxx)
-	{6001, "ErrorCodeQueryDataError", "Database Errors (6xxx)", 500, "critical", "Database query error"},
-	{6002, "ErrorCodeUpdateDataError", "Database Errors (6xxx)", 500, "critical", "Database update error"},
-	{6003, "ErrorCodeInsertDataError", "Database Errors (6xxx)", 500, "critical", "Database insert error"},
-	{6004, "ErrorCodeDeleteDataError", "Database Errors (6xxx)", 500, "critical", "Database delete error"},
-	{6005, "ErrorCodeDatabaseConnectionFailed", "Database Errors (6xxx)", 500, "critical", "Database connection failed"},
-
-	// Quota Errors (7xxx)
-	{7001, "ErrorCodeInsufficientUserQuota", "Quota Errors (7xxx)", 402, "warning", "Insufficient user quota"},
-	{7002, "ErrorCodePreConsumeTokenQuotaFailed", "Quota Errors (7xxx)", 500, "error", "Failed to pre-consume token quota"},
-	{7003, "ErrorCodeQuotaExceeded", "Quota Errors (7xxx)", 402, "warning", "User quota exceeded"},
+// collectErrorDocs builds the documentation rows from the error registry
+func collectErrorDocs() []ErrorDoc {
+	infos := types.ListAllErrors()
+	docs := make([]ErrorDoc, 0, len(infos))
+	for _, info := range infos {
+		description := info.Messages["en"]
+		if info.Deprecated {
+			description = "**Deprecated** " + description
+			if info.ReplacedBy != 0 {
+				description += fmt.Sprintf(" (use %d)", int(info.ReplacedBy))
+			}
+		}
+		docs = append(docs, ErrorDoc{
+			Code:        int(info.Code),
+			Name:        info.Name,
+			Category:    getCategory(info.Category),
+			HTTPStatus:  info.HTTPStatus,
+			Level:       info.Code.DefaultLevel().String(),
+			Retry:       info.Code.DefaultRetry().String(),
+			Description: description,
+		})
+	}
+	return docs
 }
 
 // markdownTemplate is the template for generating the documentation
@@ -114,16 +92,12 @@ This document provides a comprehensive reference of all error codes used in the
 
 ### Error Code Categories
 
-| Category | Range | Description |
-|----------|-------|-------------|
-| General Errors | 1xxx | General request and validation errors |
-| System Errors | 2xxx | Internal system errors |
-| Channel Errors | 3xxx | Channel and provider-related errors |
-| Client Errors | 4xxx | Client request errors |
-| Upstream Errors | 5xxx | Upstream provider errors |
-| Database Errors | 6xxx | Database operation errors |
-| Quota Errors | 7xxx | Quota and billing errors |
+Each category sets the defaults of its codes; individual codes may override the retry decision, message exposure and channel penalty.
 
+| Category | Range | Expose Message | Level | Retry | Penalize Channel |
+|----------|-------|----------------|-------|-------|------------------|
+{{range .CategoryDocs}}| {{.Name}} | {{.Range}} | {{.ExposeMessage}} | {{.Level}} | {{.Retry}} | {{.PenalizeChannel}} |
+{{end}}
 ### Error Levels
 
 | Level | Description | Color |
@@ -133,14 +107,22 @@ This document provides a comprehensive reference of all error codes used in the
 | error | Error events that might allow continuation | Red |
 | critical | Critical errors that may cause termination | Magenta |
 
+### Retry Decisions
+
+| Retry | Description |
+|-------|-------------|
+| none | Do not retry the request |
+| same_channel | Retry on the same channel after a short backoff |
+| other_channel | Retry on a different channel |
+
 ---
 
 {{range .Categories}}
 ## {{.Category}}
 
-| Code | Constant Name | HTTP Status | Level | Description |
-|------|---------------|-------------|-------|-------------|
-{{range .Errors}}| {{.Code}} | ` + "`" + `{{.Name}}` + "`" + ` | {{.HTTPStatus}} | {{.Level}} | {{.Description}} |
+| Code | Name | HTTP Status | Level | Retry | Description |
+|------|---------------|-------------|-------|-------|-------------|
+{{range .Errors}}| {{.Code}} | ` + "`" + `{{.Name}}` + "`" + ` | {{.HTTPStatus}} | {{.Level}} | {{.Retry}} | {{.Description}} |
 {{end}}
 
 ---
@@ -253,35 +235,36 @@ type CategoryData struct {
 
 // TemplateData holds data for the markdown template
 type TemplateData struct {
-	Timestamp  string
-	Categories []CategoryData
-	TotalCount int
+	Timestamp    string
+	CategoryDocs []CategoryDoc
+	Categories   []CategoryData
+	TotalCount   int
 }
 
 func main() {
 	// Generate current timestamp
 	timestamp := "2026-02-26"
 
+	errorCodes := collectErrorDocs()
+
 	// Group errors by category
 	categoryMap := make(map[string][]ErrorDoc)
 	for _, err := range errorCodes {
 		categoryMap[err.Category] = append(categoryMap[err.Category], err)
 	}
 
-	// Convert to sorted categories
+	// Convert to categories in range order, skipping empty ones
 	var categories []CategoryData
-	for cat, errs := range categoryMap {
-		categories = append(categories, CategoryData{
-			Category: cat,
-			Errors:   errs,
-		})
+	for _, info := range types.ListCategories() {
+		cat := getCategory(info.Category)
+		if errs, ok := categoryMap[cat]; ok {
+			categories = append(categories, CategoryData{
+				Category: cat,
+				Errors:   errs,
+			})
+		}
 	}
 
-	// Sort categories by name
-	sort.Slice(categories, func(i, j int) bool {
-		return categories[i].Category < categories[j].Category
-	})
-
 	// Sort errors within each category by code
 	for i := range categories {
 		sort.Slice(categories[i].Errors, func(j, k int) bool {
@@ -291,9 +274,10 @@ func main() {
 
 	// Prepare template data
 	data := TemplateData{
-		Timestamp:  timestamp,
-		Categories: categories,
-		TotalCount: len(errorCodes),
+		Timestamp:    timestamp,
+		CategoryDocs: collectCategoryDocs(),
+		Categories:   categories,
+		TotalCount:   len(errorCodes),
 	}
 
 	// Parse and execute template
@@ -9,13 +9,18 @@ import (
 )
 
 type OpenAIError struct {
-	Message  string          `json:"message"`
-	Type     string          `json:"type"`
-	Param    string          `json:"param"`
-	Code     any             `json:"code"`
-	Metadata json.RawMessage `json:"metadata,omitempty"`
+	Message     string          `json:"message"`
+	Type        string          `json:"type"`
+	Param       string          `json:"param"`
+	Code        UpstreamCode    `json:"code"`
+	NumericCode ErrorCode       `json:"numeric_code,omitempty"` // extension: numeric error code of this gateway
+	Metadata    json.RawMessage `json:"metadata,omitempty"`
 }
 
+// LegacyOpenAIErrorCode makes ToOpenAIError put the numeric error code into "code"
+// instead of the stable string code, for consumers that still parse the number
+var LegacyOpenAIErrorCode = false
+
 type ClaudeError struct {
 	Type    string `json:"type,omitempty"`
 	Message string `json:"message,omitempty"`
@@ -40,6 +45,11 @@ type NewAPIError struct {
 	recordErrorLog *bool
 	errorType      ErrorType
 	errorCode      ErrorCode // NEW: numeric error code from error_code.go
+	upstreamCode   string    // original code string reported by the upstream, empty if none
+	passUpstream   bool
+	param          string
+	retry          RetryAdvice     // retry hints set through options, see RetryAdvice()
+	streamProgress *StreamProgress // set for errors raised mid-stream, see StreamProgress()
 	StatusCode     int
 	Level          ErrorLevel // NEW: error severity level
 	Metadata       json.RawMessage
@@ -60,6 +70,34 @@ func (e *NewAPIError) GetErrorCode() ErrorCode {
 	return e.errorCode
 }
 
+// GetUpstreamCode returns the original code string reported by the upstream, if any
+func (e *NewAPIError) GetUpstreamCode() string {
+	if e == nil {
+		return ""
+	}
+	return e.upstreamCode
+}
+
+// openAIErrorCode returns the code rendered into OpenAI errors
+func (e *NewAPIError) openAIErrorCode() UpstreamCode {
+	if e.passUpstream && e.upstreamCode != "" {
+		if e.errorType == ErrorTypeOpenAIError {
+			// keep the shape the upstream used, e.g. 429 rather than "429"
+			if openAIError, ok := e.RelayError.(OpenAIError); ok && openAIError.Code.String() == e.upstreamCode {
+				return openAIError.Code
+			}
+		}
+		return NewUpstreamCode(e.upstreamCode)
+	}
+	if LegacyOpenAIErrorCode {
+		return NewUpstreamCode(int(e.errorCode))
+	}
+	if name := e.errorCode.String(); name != "" {
+		return NewUpstreamCode(name)
+	}
+	return NewUpstreamCode("unknown_error")
+}
+
 func (e *NewAPIError) GetErrorType() ErrorType {
 	if e == nil {
 		return ""
@@ -92,32 +130,53 @@ func (e *NewAPIError) MaskSensitiveError() string {
 	return common.MaskSensitiveInfo(errStr)
 }
 
+// Clone returns a copy of the error that can be changed without affecting the original
+func (e *NewAPIError) Clone() *NewAPIError {
+	if e == nil {
+		return nil
+	}
+	clone := *e
+	if e.recordErrorLog != nil {
+		clone.recordErrorLog = common.GetPointer(*e.recordErrorLog)
+	}
+	if e.Metadata != nil {
+		clone.Metadata = append(json.RawMessage(nil), e.Metadata...)
+	}
+	return &clone
+}
+
+// wrapNewAPIError returns a copy of found that wraps err, the error found was extracted from,
+// with the options applied to the copy. found itself is never modified, so shared errors stay
+// safe to use from several goroutines, and the cause chain still leads back to found.
+func wrapNewAPIError(found *NewAPIError, err error, ops ...NewAPIErrorOptions) *NewAPIError {
+	clone := found.Clone()
+	clone.Err = err
+	for _, op := range ops {
+		op(clone)
+	}
+	return clone
+}
+
 func (e *NewAPIError) SetMessage(message string) {
 	e.Err = errors.New(message)
 }
 
+// ToOpenAIError renders the error with an OpenAI-vocabulary type and a stable string code,
+// keeping the numeric error code in NumericCode
 func (e *NewAPIError) ToOpenAIError() OpenAIError {
-	var result OpenAIError
-	switch e.errorType {
-	case ErrorTypeOpenAIError:
+	result := OpenAIError{
+		Message:     e.Error(),
+		Type:        openAIErrorTypeFor(e.errorCode, e.StatusCode),
+		Param:       e.param,
+		Code:        e.openAIErrorCode(),
+		NumericCode: e.errorCode,
+	}
+	if e.errorType == ErrorTypeOpenAIError {
 		if openAIError, ok := e.RelayError.(OpenAIError); ok {
-			result = openAIError
-		}
-	case ErrorTypeClaudeError:
-		if claudeError, ok := e.RelayError.(ClaudeError); ok {
-			result = OpenAIError{
-				Message: e.Error(),
-				Type:    claudeError.Type,
-				Param:   "",
-				Code:    e.errorCode,
+			if result.Param == "" {
+				result.Param = openAIError.Param
 			}
-		}
-	default:
-		result = OpenAIError{
-			Message: e.Error(),
-			Type:    string(e.errorType),
-			Param:   "",
-			Code:    e.errorCode,
+			result.Metadata = openAIError.Metadata
 		}
 	}
 	if e.errorCode != ErrorCodeCountTokenFailed {
@@ -129,25 +188,14 @@ func (e *NewAPIError) ToOpenAIError() OpenAIError {
 	return result
 }
 
+// ToClaudeError renders the error with an official Anthropic error type derived from
+// the error code and status, so Anthropic SDKs pick the right exception and retry behaviour.
+// Upstream Claude types are not passed through: an authentication_error for our channel key
+// is not the caller's.
 func (e *NewAPIError) ToClaudeError() ClaudeError {
-	var result ClaudeError
-	switch e.errorType {
-	case ErrorTypeOpenAIError:
-		if openAIError, ok := e.RelayError.(OpenAIError); ok {
-			result = ClaudeError{
-				Message: e.Error(),
-				Type:    fmt.Sprintf("%v", openAIError.Code),
-			}
-		}
-	case ErrorTypeClaudeError:
-		if claudeError, ok := e.RelayError.(ClaudeError); ok {
-			result = claudeError
-		}
-	default:
-		result = ClaudeError{
-			Message: e.Error(),
-			Type:    string(e.errorType),
-		}
+	result := ClaudeError{
+		Message: e.Error(),
+		Type:    claudeErrorTypeFor(e.errorCode, e.StatusCode),
 	}
 	if e.errorCode != ErrorCodeCountTokenFailed {
 		result.Message = common.MaskSensitiveInfo(result.Message)
@@ -164,10 +212,7 @@ func NewError(err error, errorCode ErrorCode, ops ...NewAPIErrorOptions) *NewAPI
 	var newErr *NewAPIError
 	// 保留深层传递的 new err
 	if errors.As(err, &newErr) {
-		for _, op := range ops {
-			op(newErr)
-		}
-		return newErr
+		return wrapNewAPIError(newErr, err, ops...)
 	}
 	e := &NewAPIError{
 		Err:        err,
@@ -191,29 +236,26 @@ func NewOpenAIError(err error, errorCode ErrorCode, statusCode int, ops ...NewAP
 			openaiError := OpenAIError{
 				Message: newErr.Error(),
 				Type:    errorCode.String(),
-				Code:    errorCode,
+				Code:    NewUpstreamCode(errorCode),
 			}
-			newErr.RelayError = openaiError
-		}
-		for _, op := range ops {
-			op(newErr)
+			ops = append([]NewAPIErrorOptions{func(e *NewAPIError) { e.RelayError = openaiError }}, ops...)
 		}
-		return newErr
+		return wrapNewAPIError(newErr, err, ops...)
 	}
 	openaiError := OpenAIError{
 		Message: err.Error(),
 		Type:    errorCode.String(),
-		Code:    errorCode,
+		Code:    NewUpstreamCode(errorCode),
 	}
-	return WithOpenAIError(openaiError, statusCode, ops...)
+	return newOpenAIRelayError(openaiError, errorCode, "", statusCode, ops...)
 }
 
 func InitOpenAIError(errorCode ErrorCode, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
 	openaiError := OpenAIError{
 		Type: errorCode.String(),
-		Code: errorCode,
+		Code: NewUpstreamCode(errorCode),
 	}
-	return WithOpenAIError(openaiError, statusCode, ops...)
+	return newOpenAIRelayError(openaiError, errorCode, "", statusCode, ops...)
 }
 
 func NewErrorWithStatusCode(err error, errorCode ErrorCode, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
@@ -236,25 +278,30 @@ func NewErrorWithStatusCode(err error, errorCode ErrorCode, statusCode int, ops
 }
 
 func WithOpenAIError(openAIError OpenAIError, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
-	code, ok := openAIError.Code.(string)
-	if !ok {
-		if openAIError.Code != nil {
-			code = fmt.Sprintf("%v", openAIError.Code)
-		} else {
-			code = "unknown_error"
+	code := openAIError.Code.String()
+	if statusCode == 0 {
+		// no HTTP status (e.g. an error inside a 200 body), many providers put it in the code
+		if n, ok := openAIError.Code.Int(); ok && n >= 400 && n < 600 {
+			statusCode = int(n)
 		}
 	}
+	errorCode := openAIUpstreamErrorCode(code, openAIError.Type, statusCode)
+	return newOpenAIRelayError(openAIError, errorCode, code, statusCode, ops...)
+}
+
+// newOpenAIRelayError builds an OpenAI-typed error; upstreamCode is empty for errors built internally
+func newOpenAIRelayError(openAIError OpenAIError, errorCode ErrorCode, upstreamCode string, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
 	if openAIError.Type == "" {
 		openAIError.Type = "upstream_error"
 	}
-	errorCode := ErrorCodeFromString(code)
 	e := &NewAPIError{
-		RelayError: openAIError,
-		errorType:  ErrorTypeOpenAIError,
-		StatusCode: statusCode,
-		Err:        errors.New(openAIError.Message),
-		errorCode:  errorCode,
-		Level:      errorCode.DefaultLevel(), // Set default level
+		RelayError:   openAIError,
+		errorType:    ErrorTypeOpenAIError,
+		StatusCode:   statusCode,
+		Err:          errors.New(openAIError.Message),
+		errorCode:    errorCode,
+		upstreamCode: upstreamCode,
+		Level:        errorCode.DefaultLevel(), // Set default level
 	}
 	// OpenRouter
 	if len(openAIError.Metadata) > 0 {
@@ -270,17 +317,19 @@ func WithOpenAIError(openAIError OpenAIError, statusCode int, ops ...NewAPIError
 }
 
 func WithClaudeError(claudeError ClaudeError, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
+	upstreamCode := claudeError.Type
 	if claudeError.Type == "" {
 		claudeError.Type = "upstream_error"
 	}
-	errorCode := ErrorCodeFromString(claudeError.Type)
+	errorCode := claudeErrorCode(upstreamCode, statusCode)
 	e := &NewAPIError{
-		RelayError: claudeError,
-		errorType:  ErrorTypeClaudeError,
-		StatusCode: statusCode,
-		Err:        errors.New(claudeError.Message),
-		errorCode:  errorCode,
-		Level:      errorCode.DefaultLevel(), // Set default level
+		RelayError:   claudeError,
+		errorType:    ErrorTypeClaudeError,
+		StatusCode:   statusCode,
+		Err:          errors.New(claudeError.Message),
+		errorCode:    errorCode,
+		upstreamCode: upstreamCode,
+		Level:        errorCode.DefaultLevel(), // Set default level
 	}
 	for _, op := range ops {
 		op(e)
@@ -292,16 +341,18 @@ func IsChannelError(err *NewAPIError) bool {
 	if err == nil {
 		return false
 	}
-	// Channel errors are in the 3xxx range (3000-3999)
-	return err.errorCode >= 3000 && err.errorCode < 4000
+	return err.errorCode.Category() == CategoryChannel
 }
 
+// IsSkipRetryError reports whether retries were explicitly ruled out with ErrOptionWithSkipRetry
+// or ErrOptionWithRetryDecision. It ignores the default decision of the error code, which
+// existing callers do not expect; RetryAdvice is the full retry recommendation.
 func IsSkipRetryError(err *NewAPIError) bool {
 	if err == nil {
 		return false
 	}
 
-	return err.skipRetry
+	return err.skipRetry || err.retry.Decision == RetryDecisionNone
 }
 
 func ErrOptionWithSkipRetry() NewAPIErrorOptions {
@@ -325,6 +376,21 @@ func ErrOptionWithHideErrMsg(replaceStr string) NewAPIErrorOptions {
 	}
 }
 
+// ErrOptionWithUpstreamCodePassthrough renders the original upstream code instead of
+// the numeric error code when the error is converted to another format
+func ErrOptionWithUpstreamCodePassthrough() NewAPIErrorOptions {
+	return func(e *NewAPIError) {
+		e.passUpstream = true
+	}
+}
+
+// ErrOptionWithParam sets the request parameter the error refers to
+func ErrOptionWithParam(param string) NewAPIErrorOptions {
+	return func(e *NewAPIError) {
+		e.param = param
+	}
+}
+
 // ErrOptionWithLevel sets a custom error level (overrides the default from error code)
 func ErrOptionWithLevel(level ErrorLevel) NewAPIErrorOptions {
 	return func(e *NewAPIError) {
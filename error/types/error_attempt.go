@@ -0,0 +1,137 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// maxAttemptMessageLength caps the message of each attempt in AttemptErrors.Summary
+const maxAttemptMessageLength = 120
+
+// Attempt records the outcome of one try of a relay request against a channel.
+// The attempt keeps a snapshot of the error taken when it is added, so later changes to the
+// caller's error do not alter the record.
+type Attempt struct {
+	ChannelID  int
+	KeyIndex   int
+	Code       ErrorCode
+	StatusCode int
+	Latency    time.Duration
+	Message    string
+	Err        *NewAPIError
+}
+
+// AttemptErrors collects the errors of every attempt of a relay request that failed over
+// across channels. errors.Is and errors.As see all attempts. It is not safe for concurrent
+// use; retries of one request run one after another.
+type AttemptErrors struct {
+	attempts []Attempt
+}
+
+// Add records a failed attempt; a nil error is ignored
+func (a *AttemptErrors) Add(channelID int, keyIndex int, latency time.Duration, err *NewAPIError) {
+	if err == nil {
+		return
+	}
+	a.attempts = append(a.attempts, Attempt{
+		ChannelID:  channelID,
+		KeyIndex:   keyIndex,
+		Code:       err.errorCode,
+		StatusCode: err.StatusCode,
+		Latency:    latency,
+		Message:    err.MaskSensitiveError(),
+		Err:        err.Clone(),
+	})
+}
+
+// Len returns the number of recorded attempts
+func (a *AttemptErrors) Len() int {
+	if a == nil {
+		return 0
+	}
+	return len(a.attempts)
+}
+
+// Attempts returns copies of the recorded attempts in order
+func (a *AttemptErrors) Attempts() []Attempt {
+	if a == nil {
+		return nil
+	}
+	attempts := make([]Attempt, len(a.attempts))
+	for i, attempt := range a.attempts {
+		attempt.Err = attempt.Err.Clone()
+		attempts[i] = attempt
+	}
+	return attempts
+}
+
+// Error implements the error interface with the attempt summary
+func (a *AttemptErrors) Error() string {
+	return a.Summary()
+}
+
+// Unwrap exposes copies of every attempt to errors.Is and errors.As
+func (a *AttemptErrors) Unwrap() []error {
+	if a == nil {
+		return nil
+	}
+	errs := make([]error, 0, len(a.attempts))
+	for _, attempt := range a.attempts {
+		errs = append(errs, attempt.Err.Clone())
+	}
+	return errs
+}
+
+// attemptRank orders errors by how much they tell the client; higher is more meaningful
+func attemptRank(err *NewAPIError) int {
+	switch {
+	case isAbandonedRequest(err):
+		// says nothing about the request or the upstream
+		return 0
+	case err.RetryAdvice().Decision == RetryDecisionNone:
+		// the request itself cannot succeed anywhere, e.g. context too long or no quota
+		return 3
+	case err.errorType != ErrorTypeNewAPIError:
+		// an upstream answered with a structured error
+		return 2
+	default:
+		// channel or transport failure
+		return 1
+	}
+}
+
+// Best returns a copy of the error that should be returned to the client: the most
+// meaningful one, and of equally meaningful errors the latest. It returns nil if no
+// attempt was recorded.
+func (a *AttemptErrors) Best() *NewAPIError {
+	if a == nil || len(a.attempts) == 0 {
+		return nil
+	}
+	best := a.attempts[0].Err
+	for _, attempt := range a.attempts[1:] {
+		if attemptRank(attempt.Err) >= attemptRank(best) {
+			best = attempt.Err
+		}
+	}
+	return best.Clone()
+}
+
+// Summary returns a one-line description of all attempts for the error log, e.g.
+// "2 attempts: #1 channel 3 key 0 429 rate_limit_exceeded 1.2s: slow down; #2 ..."
+func (a *AttemptErrors) Summary() string {
+	if a == nil || len(a.attempts) == 0 {
+		return "no attempts"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d attempts: ", len(a.attempts))
+	for i, attempt := range a.attempts {
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		fmt.Fprintf(&b, "#%d channel %d key %d %d %s %s: %s",
+			i+1, attempt.ChannelID, attempt.KeyIndex, attempt.StatusCode, attempt.Code.String(),
+			attempt.Latency.Round(time.Millisecond), truncateMessage(attempt.Message, maxAttemptMessageLength))
+	}
+	return b.String()
+}
@@ -0,0 +1,117 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAttemptErrorsBest verifies the most meaningful attempt is chosen for the client
+func TestAttemptErrorsBest(t *testing.T) {
+	refused := NewError(errors.New("dial tcp: connection refused"), ErrorCodeUpstreamConnectionRefused)
+	limited := WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests)
+	tooLong := WithOpenAIError(OpenAIError{Message: "too long", Code: NewUpstreamCode("context_length_exceeded")}, http.StatusBadRequest)
+	timedOut := NewError(context.DeadlineExceeded, ErrorCodeUpstreamTimeout)
+	closed := NewError(context.Canceled, ErrorCodeClientClosedRequest)
+
+	tests := []struct {
+		name     string
+		errs     []*NewAPIError
+		expected *NewAPIError
+	}{
+		{"Single", []*NewAPIError{refused}, refused},
+		{"Upstream answer beats transport failure", []*NewAPIError{limited, refused}, limited},
+		{"Request fault beats everything", []*NewAPIError{tooLong, limited, refused}, tooLong},
+		{"Latest of equal rank", []*NewAPIError{refused, timedOut}, timedOut},
+		{"Client closed ranks last", []*NewAPIError{refused, closed}, refused},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts AttemptErrors
+			for i, err := range tt.errs {
+				attempts.Add(i+1, 0, time.Second, err)
+			}
+			if got := attempts.Best(); got == nil || got.GetErrorCode() != tt.expected.GetErrorCode() {
+				t.Errorf("Best() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	var empty AttemptErrors
+	if empty.Best() != nil {
+		t.Errorf("Best() on no attempts = %v, want nil", empty.Best())
+	}
+}
+
+// TestAttemptErrorsUnwrap verifies errors.Is and errors.As see every attempt
+func TestAttemptErrorsUnwrap(t *testing.T) {
+	var attempts AttemptErrors
+	attempts.Add(1, 0, 2*time.Second, NewError(context.DeadlineExceeded, ErrorCodeUpstreamTimeout))
+	attempts.Add(2, 1, 10*time.Millisecond, WithClaudeError(ClaudeError{Type: ClaudeErrorTypeOverloaded, Message: "Overloaded"}, StatusUpstreamOverloaded))
+	attempts.Add(3, 0, 0, nil)
+
+	if attempts.Len() != 2 {
+		t.Fatalf("Len() = %d, want 2", attempts.Len())
+	}
+	var err error = &attempts
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("errors.Is(attempts, context.DeadlineExceeded) = false, want true")
+	}
+	var apiErr *NewAPIError
+	if !errors.As(err, &apiErr) || apiErr.GetErrorCode() != ErrorCodeUpstreamTimeout {
+		t.Errorf("errors.As(attempts, *NewAPIError) did not find the first attempt")
+	}
+}
+
+// TestAttemptErrorsSummary verifies the summary lists every attempt compactly
+func TestAttemptErrorsSummary(t *testing.T) {
+	var attempts AttemptErrors
+	attempts.Add(3, 0, 1200*time.Millisecond, WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests))
+	attempts.Add(7, 2, 30*time.Second, NewError(errors.New(strings.Repeat("x", 500)), ErrorCodeUpstreamTimeout))
+
+	summary := attempts.Summary()
+	expectedPrefix := "2 attempts: #1 channel 3 key 0 429 rate_limit_exceeded 1.2s: slow down; #2 channel 7 key 2 504 upstream_timeout 30s: "
+	if !strings.HasPrefix(summary, expectedPrefix) {
+		t.Errorf("Summary() = %q, want prefix %q", summary, expectedPrefix)
+	}
+	if len(summary) > len(expectedPrefix)+maxAttemptMessageLength+len("...") {
+		t.Errorf("Summary() length = %d, long messages should be truncated", len(summary))
+	}
+	if attempts.Error() != summary {
+		t.Errorf("Error() = %q, want the summary", attempts.Error())
+	}
+
+	// the record keeps its own copy of the attempt
+	attempts.Attempts()[0].Err.SetMessage("changed")
+	if attempts.Attempts()[0].Message != "slow down" {
+		t.Errorf("Attempts()[0].Message = %q, want %q", attempts.Attempts()[0].Message, "slow down")
+	}
+	if got := attempts.Attempts()[0].Err.Error(); got != "slow down" {
+		t.Errorf("Attempts()[0].Err = %q after changing a returned copy, want %q", got, "slow down")
+	}
+}
+
+// TestAttemptErrorsSnapshot verifies changes to the caller's error after Add do not alter the record
+func TestAttemptErrorsSnapshot(t *testing.T) {
+	orig := WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests)
+	var attempts AttemptErrors
+	attempts.Add(1, 0, time.Second, orig)
+
+	orig.StatusCode = http.StatusInternalServerError
+	orig.SetMessage("changed")
+	if got := attempts.Attempts()[0].Err; got.StatusCode != http.StatusTooManyRequests || got.Error() != "slow down" {
+		t.Errorf("Attempts()[0].Err = %d %q, want %d %q", got.StatusCode, got.Error(), http.StatusTooManyRequests, "slow down")
+	}
+	if best := attempts.Best(); best.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Best() StatusCode = %d, want %d", best.StatusCode, http.StatusTooManyRequests)
+	}
+
+	attempts.Best().StatusCode = http.StatusBadGateway
+	if best := attempts.Best(); best.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("Best() StatusCode = %d after changing a returned copy, want %d", best.StatusCode, http.StatusTooManyRequests)
+	}
+}
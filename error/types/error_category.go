@@ -0,0 +1,319 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCategory groups error codes by their numeric range. Categories are sentinel
+// errors: errors.Is(err, CategoryChannel) reports whether err carries a channel error code.
+type ErrorCategory string
+
+const (
+	CategoryGeneral  ErrorCategory = "general"
+	CategorySystem   ErrorCategory = "system"
+	CategoryChannel  ErrorCategory = "channel"
+	CategoryClient   ErrorCategory = "client"
+	CategoryUpstream ErrorCategory = "upstream"
+	CategoryDatabase ErrorCategory = "database"
+	CategoryQuota    ErrorCategory = "quota"
+	CategoryAuth     ErrorCategory = "auth"
+	CategoryMisc     ErrorCategory = "misc"
+)
+
+// CategoryPolicy holds the defaults a category gives its error codes.
+// Codes inherit them unless their ErrorInfo overrides a field.
+type CategoryPolicy struct {
+	ExposeMessage   bool          // show the error's own message to the client instead of the localized generic one
+	Level           ErrorLevel    // level of codes in the range that are not registered
+	Retry           RetryDecision // default retry decision
+	PenalizeChannel bool          // count the error against the channel that served the request
+}
+
+// CategoryInfo describes an error category: its code range, localized names and policy
+type CategoryInfo struct {
+	Category ErrorCategory
+	Min      ErrorCode // first code of the range
+	Max      ErrorCode // last code of the range
+	Names    ErrorMessage
+	Policy   CategoryPolicy
+}
+
+// errorCategories is the single source of truth for the numeric ranges, sorted by range
+var errorCategories = []CategoryInfo{
+	{
+		Category: CategoryGeneral,
+		Min:      1000,
+		Max:      1999,
+		Names: ErrorMessage{
+			"en": "General Errors",
+			"zh": "通用错误",
+			"ja": "一般エラー",
+			"fr": "Erreurs générales",
+			"ru": "Общие ошибки",
+			"vi": "Lỗi chung",
+		},
+		Policy: CategoryPolicy{ExposeMessage: true, Level: ErrorLevelWarning, Retry: RetryDecisionNone},
+	},
+	{
+		Category: CategorySystem,
+		Min:      2000,
+		Max:      2999,
+		Names: ErrorMessage{
+			"en": "System Errors",
+			"zh": "系统错误",
+			"ja": "システムエラー",
+			"fr": "Erreurs système",
+			"ru": "Системные ошибки",
+			"vi": "Lỗi hệ thống",
+		},
+		// internal failures may leak implementation details
+		Policy: CategoryPolicy{ExposeMessage: false, Level: ErrorLevelError, Retry: RetryDecisionNone},
+	},
+	{
+		Category: CategoryChannel,
+		Min:      3000,
+		Max:      3999,
+		Names: ErrorMessage{
+			"en": "Channel Errors",
+			"zh": "渠道错误",
+			"ja": "チャネルエラー",
+			"fr": "Erreurs de canal",
+			"ru": "Ошибки канала",
+			"vi": "Lỗi kênh",
+		},
+		// channel messages may contain keys or configuration
+		Policy: CategoryPolicy{ExposeMessage: false, Level: ErrorLevelError, Retry: RetryDecisionOtherChannel, PenalizeChannel: true},
+	},
+	{
+		Category: CategoryClient,
+		Min:      4000,
+		Max:      4999,
+		Names: ErrorMessage{
+			"en": "Client Errors",
+			"zh": "客户端错误",
+			"ja": "クライアントエラー",
+			"fr": "Erreurs client",
+			"ru": "Ошибки клиента",
+			"vi": "Lỗi máy khách",
+		},
+		Policy: CategoryPolicy{ExposeMessage: true, Level: ErrorLevelWarning, Retry: RetryDecisionNone},
+	},
+	{
+		Category: CategoryUpstream,
+		Min:      5000,
+		Max:      5999,
+		Names: ErrorMessage{
+			"en": "Upstream Errors",
+			"zh": "上游错误",
+			"ja": "上流エラー",
+			"fr": "Erreurs en amont",
+			"ru": "Ошибки вышестоящего сервиса",
+			"vi": "Lỗi thượng nguồn",
+		},
+		Policy: CategoryPolicy{ExposeMessage: true, Level: ErrorLevelError, Retry: RetryDecisionOtherChannel, PenalizeChannel: true},
+	},
+	{
+		Category: CategoryDatabase,
+		Min:      6000,
+		Max:      6999,
+		Names: ErrorMessage{
+			"en": "Database Errors",
+			"zh": "数据库错误",
+			"ja": "データベースエラー",
+			"fr": "Erreurs de base de données",
+			"ru": "Ошибки базы данных",
+			"vi": "Lỗi cơ sở dữ liệu",
+		},
+		Policy: CategoryPolicy{ExposeMessage: false, Level: ErrorLevelError, Retry: RetryDecisionNone},
+	},
+	{
+		Category: CategoryQuota,
+		Min:      7000,
+		Max:      7999,
+		Names: ErrorMessage{
+			"en": "Quota Errors",
+			"zh": "配额错误",
+			"ja": "クォータエラー",
+			"fr": "Erreurs de quota",
+			"ru": "Ошибки квоты",
+			"vi": "Lỗi hạn mức",
+		},
+		Policy: CategoryPolicy{ExposeMessage: true, Level: ErrorLevelWarning, Retry: RetryDecisionNone},
+	},
+	{
+		Category: CategoryAuth,
+		Min:      8000,
+		Max:      8999,
+		Names: ErrorMessage{
+			"en": "Authentication Errors",
+			"zh": "认证错误",
+			"ja": "認証エラー",
+			"fr": "Erreurs d'authentification",
+			"ru": "Ошибки аутентификации",
+			"vi": "Lỗi xác thực",
+		},
+		Policy: CategoryPolicy{ExposeMessage: true, Level: ErrorLevelWarning, Retry: RetryDecisionNone},
+	},
+	{
+		Category: CategoryMisc,
+		Min:      9000,
+		Max:      9999,
+		Names: ErrorMessage{
+			"en": "Miscellaneous Errors",
+			"zh": "其他错误",
+			"ja": "その他のエラー",
+			"fr": "Erreurs diverses",
+			"ru": "Прочие ошибки",
+			"vi": "Lỗi khác",
+		},
+		Policy: CategoryPolicy{ExposeMessage: true, Level: ErrorLevelWarning, Retry: RetryDecisionNone},
+	},
+}
+
+// categoryInfo returns the description of a category
+func categoryInfo(c ErrorCategory) (CategoryInfo, bool) {
+	for _, info := range errorCategories {
+		if info.Category == c {
+			return info, true
+		}
+	}
+	return CategoryInfo{}, false
+}
+
+// categoryForCode returns the category whose range contains the code, empty if there is none
+func categoryForCode(code ErrorCode) ErrorCategory {
+	for _, info := range errorCategories {
+		if code >= info.Min && code <= info.Max {
+			return info.Category
+		}
+	}
+	return ""
+}
+
+// ListCategories returns all error categories sorted by code range
+func ListCategories() []CategoryInfo {
+	return append([]CategoryInfo(nil), errorCategories...)
+}
+
+// IsValid checks if the category is a known category
+func (c ErrorCategory) IsValid() bool {
+	_, ok := categoryInfo(c)
+	return ok
+}
+
+// Contains reports whether the code lies in the range of the category
+func (c ErrorCategory) Contains(code ErrorCode) bool {
+	return c != "" && categoryForCode(code) == c
+}
+
+// Range returns the first and last code of the category, e.g. "5xxx" is 5000-5999
+func (c ErrorCategory) Range() (ErrorCode, ErrorCode) {
+	info, _ := categoryInfo(c)
+	return info.Min, info.Max
+}
+
+// RangeLabel returns the range in the "5xxx" notation used by the documentation
+func (c ErrorCategory) RangeLabel() string {
+	info, ok := categoryInfo(c)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%dxxx", info.Min/1000)
+}
+
+// LocalizedName returns the display name of the category, falling back to English
+func (c ErrorCategory) LocalizedName(lang string) string {
+	info, ok := categoryInfo(c)
+	if !ok {
+		return string(c)
+	}
+	if name, ok := info.Names[lang]; ok {
+		return name
+	}
+	return info.Names["en"]
+}
+
+// Policy returns the defaults the category gives its codes
+func (c ErrorCategory) Policy() CategoryPolicy {
+	info, ok := categoryInfo(c)
+	if !ok {
+		// unknown codes are treated like internal failures
+		return CategoryPolicy{Level: ErrorLevelError, Retry: RetryDecisionNone}
+	}
+	return info.Policy
+}
+
+// Error implements the error interface so categories can be used as errors.Is targets
+func (c ErrorCategory) Error() string {
+	return string(c)
+}
+
+// Category returns the category of the error code, empty if the code is outside the known ranges
+func (c ErrorCode) Category() ErrorCategory {
+	return categoryForCode(c)
+}
+
+// ExposeMessage reports whether the error's own message may be shown to the client
+func (c ErrorCode) ExposeMessage() bool {
+	if info, ok := errorRegistry[c]; ok && info.ExposeMessage != nil {
+		return *info.ExposeMessage
+	}
+	return c.Category().Policy().ExposeMessage
+}
+
+// PenalizesChannel reports whether the error counts against the channel that served the request
+func (c ErrorCode) PenalizesChannel() bool {
+	if info, ok := errorRegistry[c]; ok && info.PenalizeChannel != nil {
+		return *info.PenalizeChannel
+	}
+	return c.Category().Policy().PenalizeChannel
+}
+
+// Error implements the error interface so error codes can be used as errors.Is targets
+func (c ErrorCode) Error() string {
+	return c.String()
+}
+
+// Is lets errors.Is match an error code against its category
+func (c ErrorCode) Is(target error) bool {
+	category, ok := target.(ErrorCategory)
+	return ok && category != "" && c.Category() == category
+}
+
+// Is lets errors.Is match the error against an ErrorCode or an ErrorCategory sentinel:
+// errors.Is(err, ErrorCodeQuotaExceeded) and errors.Is(err, CategoryQuota) both work
+// anywhere in a wrapped chain.
+func (e *NewAPIError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	switch t := target.(type) {
+	case ErrorCode:
+		return e.errorCode == t
+	case ErrorCategory:
+		return e.errorCode.Is(t)
+	default:
+		return false
+	}
+}
+
+// IsClientError reports whether err carries a client error code (4xxx)
+func IsClientError(err error) bool {
+	return errors.Is(err, CategoryClient)
+}
+
+// IsUpstreamError reports whether err carries an upstream error code (5xxx)
+func IsUpstreamError(err error) bool {
+	return errors.Is(err, CategoryUpstream)
+}
+
+// IsDatabaseError reports whether err carries a database error code (6xxx)
+func IsDatabaseError(err error) bool {
+	return errors.Is(err, CategoryDatabase)
+}
+
+// IsQuotaError reports whether err carries a quota error code (7xxx)
+func IsQuotaError(err error) bool {
+	return errors.Is(err, CategoryQuota)
+}
@@ -0,0 +1,193 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestErrorsIsErrorCode verifies error codes work as errors.Is targets through wrapped chains
+func TestErrorsIsErrorCode(t *testing.T) {
+	quota := NewError(errors.New("no quota"), ErrorCodeQuotaExceeded)
+	wrapped := fmt.Errorf("pre-consume: %w", quota)
+
+	if !errors.Is(wrapped, ErrorCodeQuotaExceeded) {
+		t.Errorf("errors.Is(wrapped, ErrorCodeQuotaExceeded) = false, want true")
+	}
+	if errors.Is(wrapped, ErrorCodeInsufficientUserQuota) {
+		t.Errorf("errors.Is(wrapped, ErrorCodeInsufficientUserQuota) = true, want false")
+	}
+	if !errors.Is(wrapped, CategoryQuota) || errors.Is(wrapped, CategoryChannel) {
+		t.Errorf("errors.Is(wrapped, category) does not match the quota category only")
+	}
+
+	var attempts AttemptErrors
+	attempts.Add(1, 0, time.Second, NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey))
+	attempts.Add(2, 0, time.Second, WithClaudeError(ClaudeError{Type: ClaudeErrorTypeOverloaded}, StatusUpstreamOverloaded))
+	if !errors.Is(&attempts, ErrorCodeUpstreamOverloaded) || !errors.Is(&attempts, CategoryChannel) {
+		t.Errorf("errors.Is(attempts, ...) does not see every attempt")
+	}
+
+	if !errors.Is(ErrorCodeRateLimitExceeded, CategoryUpstream) {
+		t.Errorf("errors.Is(ErrorCodeRateLimitExceeded, CategoryUpstream) = false, want true")
+	}
+	if ErrorCodeRateLimitExceeded.Error() != "rate_limit_exceeded" || CategoryUpstream.Error() != "upstream" {
+		t.Errorf("Error() of sentinels = %q, %q", ErrorCodeRateLimitExceeded.Error(), CategoryUpstream.Error())
+	}
+}
+
+// TestCategoryPredicates verifies the category predicates across error shapes
+func TestCategoryPredicates(t *testing.T) {
+	var nilErr *NewAPIError
+	tests := []struct {
+		name     string
+		err      error
+		client   bool
+		upstream bool
+		database bool
+		quota    bool
+	}{
+		{"Client", NewError(errors.New("bad token"), ErrorCodeUnauthorized), true, false, false, false},
+		{"Upstream", WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests), false, true, false, false},
+		{"Database wrapped", fmt.Errorf("save log: %w", NewError(errors.New("db down"), ErrorCodeInsertDataError)), false, false, true, false},
+		{"Quota", NewError(errors.New("no quota"), ErrorCodeInsufficientUserQuota), false, false, false, true},
+		{"Plain error", errors.New("plain"), false, false, false, false},
+		{"Nil", nil, false, false, false, false},
+		{"Typed nil", nilErr, false, false, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsClientError(tt.err); got != tt.client {
+				t.Errorf("IsClientError() = %v, want %v", got, tt.client)
+			}
+			if got := IsUpstreamError(tt.err); got != tt.upstream {
+				t.Errorf("IsUpstreamError() = %v, want %v", got, tt.upstream)
+			}
+			if got := IsDatabaseError(tt.err); got != tt.database {
+				t.Errorf("IsDatabaseError() = %v, want %v", got, tt.database)
+			}
+			if got := IsQuotaError(tt.err); got != tt.quota {
+				t.Errorf("IsQuotaError() = %v, want %v", got, tt.quota)
+			}
+		})
+	}
+}
+
+// TestErrorCategoryRanges verifies every code maps to the category whose range contains it
+func TestErrorCategoryRanges(t *testing.T) {
+	tests := []struct {
+		code     ErrorCode
+		expected ErrorCategory
+	}{
+		{1000, CategoryGeneral},
+		{1999, CategoryGeneral},
+		{2000, CategorySystem},
+		{ErrorCodeChannelNoAvailableKey, CategoryChannel},
+		{ErrorCodeUnauthorized, CategoryClient},
+		{ErrorCodeRateLimitExceeded, CategoryUpstream},
+		{ErrorCodeInsertDataError, CategoryDatabase},
+		{ErrorCodeQuotaExceeded, CategoryQuota},
+		{8001, CategoryAuth},
+		{9999, CategoryMisc},
+		{999, ""},
+		{10000, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(int(tt.code)), func(t *testing.T) {
+			if got := tt.code.Category(); got != tt.expected {
+				t.Errorf("ErrorCode(%d).Category() = %q, want %q", tt.code, got, tt.expected)
+			}
+			if tt.expected != "" && !tt.expected.Contains(tt.code) {
+				t.Errorf("%q.Contains(%d) = false, want true", tt.expected, tt.code)
+			}
+		})
+	}
+
+	categories := ListCategories()
+	for i, info := range categories {
+		if !info.Category.IsValid() {
+			t.Errorf("category %q is not valid", info.Category)
+		}
+		if i > 0 && categories[i-1].Max+1 != info.Min {
+			t.Errorf("category %q does not start right after %q", info.Category, categories[i-1].Category)
+		}
+		for _, lang := range GetSupportedLanguages() {
+			if info.Names[lang] == "" {
+				t.Errorf("category %q has no %q name", info.Category, lang)
+			}
+		}
+	}
+	if ErrorCategory("bogus").IsValid() {
+		t.Errorf("ErrorCategory(%q).IsValid() = true, want false", "bogus")
+	}
+}
+
+// TestErrorCategoryLocalizedName verifies localized names and the English fallback
+func TestErrorCategoryLocalizedName(t *testing.T) {
+	tests := []struct {
+		category ErrorCategory
+		lang     string
+		expected string
+	}{
+		{CategoryUpstream, "en", "Upstream Errors"},
+		{CategoryUpstream, "zh", "上游错误"},
+		{CategoryQuota, "de", "Quota Errors"},
+		{ErrorCategory("bogus"), "en", "bogus"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.category.LocalizedName(tt.lang); got != tt.expected {
+			t.Errorf("%q.LocalizedName(%q) = %q, want %q", tt.category, tt.lang, got, tt.expected)
+		}
+	}
+	if got := CategoryUpstream.RangeLabel(); got != "5xxx" {
+		t.Errorf("RangeLabel() = %q, want %q", got, "5xxx")
+	}
+}
+
+// TestCategoryPolicyInheritance verifies codes inherit the policy of their category unless they override it
+func TestCategoryPolicyInheritance(t *testing.T) {
+	tests := []struct {
+		name             string
+		code             ErrorCode
+		expectedExpose   bool
+		expectedLevel    ErrorLevel
+		expectedRetry    RetryDecision
+		expectedPenalize bool
+	}{
+		{"Channel inherits", ErrorCodeChannelNoAvailableKey, false, ErrorLevelError, RetryDecisionOtherChannel, true},
+		{"Upstream inherits", ErrorCodeBadResponse, true, ErrorLevelError, RetryDecisionOtherChannel, true},
+		{"Client inherits", ErrorCodeBadRequestBody, true, ErrorLevelWarning, RetryDecisionNone, false},
+		{"System inherits", ErrorCodeJsonMarshalFailed, false, ErrorLevelError, RetryDecisionNone, false},
+		{"Transport overrides system", ErrorCodeUpstreamTimeout, false, ErrorLevelWarning, RetryDecisionOtherChannel, true},
+		{"Prompt blocked overrides upstream", ErrorCodePromptBlocked, true, ErrorLevelWarning, RetryDecisionNone, false},
+		{"Client closed overrides level", ErrorCodeClientClosedRequest, true, ErrorLevelInfo, RetryDecisionNone, false},
+		{"Unregistered code in range", 5999, true, ErrorLevelError, RetryDecisionOtherChannel, true},
+		{"Unknown range", 42, false, ErrorLevelError, RetryDecisionNone, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.code.ExposeMessage(); got != tt.expectedExpose {
+				t.Errorf("ExposeMessage() = %v, want %v", got, tt.expectedExpose)
+			}
+			if got := tt.code.DefaultLevel(); got != tt.expectedLevel {
+				t.Errorf("DefaultLevel() = %v, want %v", got, tt.expectedLevel)
+			}
+			if got := tt.code.DefaultRetry(); got != tt.expectedRetry {
+				t.Errorf("DefaultRetry() = %v, want %v", got, tt.expectedRetry)
+			}
+			if got := tt.code.PenalizesChannel(); got != tt.expectedPenalize {
+				t.Errorf("PenalizesChannel() = %v, want %v", got, tt.expectedPenalize)
+			}
+		})
+	}
+
+	if got := ErrorCode(8999).DefaultLevel(); got != CategoryAuth.Policy().Level {
+		t.Errorf("unregistered DefaultLevel() = %v, want %v", got, CategoryAuth.Policy().Level)
+	}
+}
@@ -0,0 +1,112 @@
+package types
+
+import "net/http"
+
+// StatusUpstreamOverloaded is the non-standard HTTP status Anthropic uses for overloaded_error
+const StatusUpstreamOverloaded = 529
+
+// Anthropic API error types
+// See https://docs.anthropic.com/en/api/errors
+const (
+	ClaudeErrorTypeInvalidRequest  = "invalid_request_error"
+	ClaudeErrorTypeAuthentication  = "authentication_error"
+	ClaudeErrorTypeBilling         = "billing_error"
+	ClaudeErrorTypePermission      = "permission_error"
+	ClaudeErrorTypeNotFound        = "not_found_error"
+	ClaudeErrorTypeRequestTooLarge = "request_too_large"
+	ClaudeErrorTypeRateLimit       = "rate_limit_error"
+	ClaudeErrorTypeAPI             = "api_error"
+	ClaudeErrorTypeTimeout         = "timeout_error"
+	ClaudeErrorTypeOverloaded      = "overloaded_error"
+)
+
+// claudeErrorTypeErrorCodes maps Anthropic error types to error codes
+var claudeErrorTypeErrorCodes = map[string]ErrorCode{
+	ClaudeErrorTypeInvalidRequest:  ErrorCodeInvalidRequest,
+	ClaudeErrorTypeAuthentication:  ErrorCodeChannelInvalidKey,
+	ClaudeErrorTypeBilling:         ErrorCodeUpstreamInsufficientQuota,
+	ClaudeErrorTypePermission:      ErrorCodeChannelPermissionDenied,
+	ClaudeErrorTypeNotFound:        ErrorCodeModelNotFound,
+	ClaudeErrorTypeRequestTooLarge: ErrorCodeRequestTooLarge,
+	ClaudeErrorTypeRateLimit:       ErrorCodeRateLimitExceeded,
+	ClaudeErrorTypeAPI:             ErrorCodeBadResponse,
+	ClaudeErrorTypeTimeout:         ErrorCodeChannelResponseTimeExceeded,
+	ClaudeErrorTypeOverloaded:      ErrorCodeUpstreamOverloaded,
+}
+
+// claudeErrorCode maps an Anthropic error type and the upstream HTTP status to an error code
+func claudeErrorCode(errorType string, statusCode int) ErrorCode {
+	// 529 always means overloaded, even when a proxy in front of Anthropic reports api_error
+	if statusCode == StatusUpstreamOverloaded {
+		return ErrorCodeUpstreamOverloaded
+	}
+	if errorType == ClaudeErrorTypeInvalidRequest && statusCode == http.StatusRequestEntityTooLarge {
+		return ErrorCodeRequestTooLarge
+	}
+	if code, ok := claudeErrorTypeErrorCodes[errorType]; ok {
+		return code
+	}
+	if code, ok := errorCodeFromOwnName(errorType); ok {
+		return code
+	}
+	if code, ok := errorCodeFromUpstreamStatus(statusCode); ok {
+		return code
+	}
+	return ErrorCodeUnknownUpstream
+}
+
+// claudeOfficialErrorTypes is the set of error types Anthropic SDKs understand
+var claudeOfficialErrorTypes = map[string]bool{
+	ClaudeErrorTypeInvalidRequest:  true,
+	ClaudeErrorTypeAuthentication:  true,
+	ClaudeErrorTypeBilling:         true,
+	ClaudeErrorTypePermission:      true,
+	ClaudeErrorTypeNotFound:        true,
+	ClaudeErrorTypeRequestTooLarge: true,
+	ClaudeErrorTypeRateLimit:       true,
+	ClaudeErrorTypeAPI:             true,
+	ClaudeErrorTypeTimeout:         true,
+	ClaudeErrorTypeOverloaded:      true,
+}
+
+// claudeErrorTypeFor derives the outbound Anthropic error type from an error code and HTTP status
+func claudeErrorTypeFor(errorCode ErrorCode, statusCode int) string {
+	switch errorCode {
+	case ErrorCodeUpstreamOverloaded, ErrorCodeServiceUnavailable, ErrorCodeChannelNoAvailableKey, ErrorCodeChannelNotAvailable:
+		return ClaudeErrorTypeOverloaded
+	case ErrorCodeRateLimitExceeded:
+		return ClaudeErrorTypeRateLimit
+	case ErrorCodeChannelResponseTimeExceeded, ErrorCodeUpstreamTimeout:
+		return ClaudeErrorTypeTimeout
+	case ErrorCodeRequestTooLarge:
+		return ClaudeErrorTypeRequestTooLarge
+	}
+	if isServerSideError(errorCode) {
+		return ClaudeErrorTypeAPI
+	}
+	if errorCode.Category() == CategoryQuota && statusCode == http.StatusPaymentRequired {
+		return ClaudeErrorTypeBilling
+	}
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return ClaudeErrorTypeAuthentication
+	case statusCode == http.StatusPaymentRequired:
+		return ClaudeErrorTypeBilling
+	case statusCode == http.StatusForbidden:
+		return ClaudeErrorTypePermission
+	case statusCode == http.StatusNotFound:
+		return ClaudeErrorTypeNotFound
+	case statusCode == http.StatusRequestEntityTooLarge:
+		return ClaudeErrorTypeRequestTooLarge
+	case statusCode == http.StatusTooManyRequests:
+		return ClaudeErrorTypeRateLimit
+	case statusCode == http.StatusGatewayTimeout:
+		return ClaudeErrorTypeTimeout
+	case statusCode == http.StatusServiceUnavailable || statusCode == StatusUpstreamOverloaded:
+		return ClaudeErrorTypeOverloaded
+	case statusCode >= http.StatusInternalServerError:
+		return ClaudeErrorTypeAPI
+	default:
+		return ClaudeErrorTypeInvalidRequest
+	}
+}
@@ -0,0 +1,99 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestWithClaudeErrorTaxonomy verifies every documented Anthropic error type maps to an error code
+func TestWithClaudeErrorTaxonomy(t *testing.T) {
+	tests := []struct {
+		name       string
+		errorType  string
+		statusCode int
+		expected   ErrorCode
+	}{
+		{"invalid_request_error", ClaudeErrorTypeInvalidRequest, http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{"authentication_error", ClaudeErrorTypeAuthentication, http.StatusUnauthorized, ErrorCodeChannelInvalidKey},
+		{"billing_error", ClaudeErrorTypeBilling, http.StatusPaymentRequired, ErrorCodeUpstreamInsufficientQuota},
+		{"permission_error", ClaudeErrorTypePermission, http.StatusForbidden, ErrorCodeChannelPermissionDenied},
+		{"not_found_error", ClaudeErrorTypeNotFound, http.StatusNotFound, ErrorCodeModelNotFound},
+		{"request_too_large", ClaudeErrorTypeRequestTooLarge, http.StatusRequestEntityTooLarge, ErrorCodeRequestTooLarge},
+		{"rate_limit_error", ClaudeErrorTypeRateLimit, http.StatusTooManyRequests, ErrorCodeRateLimitExceeded},
+		{"api_error", ClaudeErrorTypeAPI, http.StatusInternalServerError, ErrorCodeBadResponse},
+		{"timeout_error", ClaudeErrorTypeTimeout, http.StatusGatewayTimeout, ErrorCodeChannelResponseTimeExceeded},
+		{"overloaded_error", ClaudeErrorTypeOverloaded, StatusUpstreamOverloaded, ErrorCodeUpstreamOverloaded},
+		{"overloaded_error behind proxy", ClaudeErrorTypeOverloaded, http.StatusServiceUnavailable, ErrorCodeUpstreamOverloaded},
+		{"api_error with 529", ClaudeErrorTypeAPI, StatusUpstreamOverloaded, ErrorCodeUpstreamOverloaded},
+		{"invalid_request_error with 413", ClaudeErrorTypeInvalidRequest, http.StatusRequestEntityTooLarge, ErrorCodeRequestTooLarge},
+		{"empty type with 429", "", http.StatusTooManyRequests, ErrorCodeRateLimitExceeded},
+		{"unknown type with 502", "proxy_error", http.StatusBadGateway, ErrorCodeBadResponse},
+		{"unknown type without status", "mystery_error", 0, ErrorCodeUnknownUpstream},
+		{"own code name", "channel_no_available_key", http.StatusServiceUnavailable, ErrorCodeChannelNoAvailableKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := WithClaudeError(ClaudeError{Type: tt.errorType, Message: "upstream failure"}, tt.statusCode)
+			if e.GetErrorCode() != tt.expected {
+				t.Errorf("WithClaudeError(%q, %d) errorCode = %d, want %d", tt.errorType, tt.statusCode, e.GetErrorCode(), tt.expected)
+			}
+			if e.Level != tt.expected.DefaultLevel() {
+				t.Errorf("WithClaudeError(%q, %d) Level = %v, want %v", tt.errorType, tt.statusCode, e.Level, tt.expected.DefaultLevel())
+			}
+			if e.GetUpstreamCode() != tt.errorType {
+				t.Errorf("WithClaudeError(%q, %d) upstreamCode = %q, want %q", tt.errorType, tt.statusCode, e.GetUpstreamCode(), tt.errorType)
+			}
+		})
+	}
+}
+
+// TestToClaudeErrorType verifies the outbound Claude type is always an official Anthropic type
+func TestToClaudeErrorType(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *NewAPIError
+		expected string
+	}{
+		{"OpenAI error with null code", WithOpenAIError(OpenAIError{Message: "system disk overloaded", Code: NewUpstreamCode(nil)}, http.StatusInternalServerError), ClaudeErrorTypeAPI},
+		{"OpenAI rate limit", WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests), ClaudeErrorTypeRateLimit},
+		{"OpenAI context length", WithOpenAIError(OpenAIError{Message: "too long", Code: NewUpstreamCode("context_length_exceeded")}, http.StatusBadRequest), ClaudeErrorTypeInvalidRequest},
+		{"Claude passthrough", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeOverloaded, Message: "Overloaded"}, StatusUpstreamOverloaded), ClaudeErrorTypeOverloaded},
+		{"Claude authentication of our key", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeAuthentication, Message: "invalid x-api-key"}, http.StatusUnauthorized), ClaudeErrorTypeAPI},
+		{"Claude permission of our key", WithClaudeError(ClaudeError{Type: ClaudeErrorTypePermission, Message: "no access to model"}, http.StatusForbidden), ClaudeErrorTypeAPI},
+		{"Claude billing of our account", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeBilling, Message: "credit balance too low"}, http.StatusPaymentRequired), ClaudeErrorTypeAPI},
+		{"Claude api_error with 529", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeAPI, Message: "Overloaded"}, StatusUpstreamOverloaded), ClaudeErrorTypeOverloaded},
+		{"Claude unofficial type", WithClaudeError(ClaudeError{Type: "proxy_error", Message: "bad gateway"}, http.StatusBadGateway), ClaudeErrorTypeAPI},
+		{"Gemini resource exhausted", WithGeminiError(GeminiError{Code: 429, Status: GeminiStatusResourceExhausted, Message: "quota"}, 429), ClaudeErrorTypeRateLimit},
+		{"Midjourney not found", WithMidjourneyError(MidjourneyError{Code: MidjourneyCodeNotFound, Description: "no task"}, http.StatusOK), ClaudeErrorTypeNotFound},
+		{"Channel invalid key", NewError(errors.New("bad key"), ErrorCodeChannelInvalidKey), ClaudeErrorTypeAPI},
+		{"No available channel", NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey), ClaudeErrorTypeOverloaded},
+		{"User quota", NewError(errors.New("no quota"), ErrorCodeInsufficientUserQuota), ClaudeErrorTypeBilling},
+		{"Client unauthorized", NewError(errors.New("bad token"), ErrorCodeUnauthorized), ClaudeErrorTypeAuthentication},
+		{"Client forbidden", NewError(errors.New("forbidden"), ErrorCodeForbidden), ClaudeErrorTypePermission},
+		{"Bad request body", NewError(errors.New("bad json"), ErrorCodeBadRequestBody), ClaudeErrorTypeInvalidRequest},
+		{"Database failure", NewError(errors.New("db down"), ErrorCodeQueryDataError), ClaudeErrorTypeAPI},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.err.ToClaudeError()
+			if result.Type != tt.expected {
+				t.Errorf("ToClaudeError() Type = %q, want %q", result.Type, tt.expected)
+			}
+			if !claudeOfficialErrorTypes[result.Type] {
+				t.Errorf("ToClaudeError() Type = %q is not an official Anthropic type", result.Type)
+			}
+		})
+	}
+}
+
+// TestToClaudeErrorHidesMessage verifies ErrOptionWithHideErrMsg also hides upstream Claude messages
+func TestToClaudeErrorHidesMessage(t *testing.T) {
+	e := WithClaudeError(ClaudeError{Type: ClaudeErrorTypeInvalidRequest, Message: "key sk-ant-123 of org acme"}, http.StatusBadRequest,
+		ErrOptionWithHideErrMsg("request rejected"))
+	if got := e.ToClaudeError().Message; got != "request rejected" {
+		t.Errorf("ToClaudeError() Message = %q, want %q", got, "request rejected")
+	}
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"context"
+	"errors"
+)
+
+// StatusClientClosedRequest is the non-standard HTTP status nginx uses when the client
+// closed the connection before the response was sent
+const StatusClientClosedRequest = 499
+
+// ClassifyRequestError builds a NewAPIError for a failed upstream call. ctx is the inbound
+// request context: if the client has gone away, the failure is reported as
+// ErrorCodeClientClosedRequest regardless of err, because the upstream did nothing wrong.
+// Otherwise err is classified by ClassifyTransportError. It returns nil if err is nil
+// and the client is still connected.
+func ClassifyRequestError(ctx context.Context, err error, ops ...NewAPIErrorOptions) *NewAPIError {
+	if ctx != nil && errors.Is(ctx.Err(), context.Canceled) {
+		if err == nil {
+			err = ctx.Err()
+		}
+		return newClientClosedError(err, ops...)
+	}
+	return ClassifyTransportError(err, ops...)
+}
+
+// newClientClosedError reports err as ErrorCodeClientClosedRequest. Nobody is waiting for
+// the answer any more, so retrying would only burn quota.
+func newClientClosedError(err error, ops ...NewAPIErrorOptions) *NewAPIError {
+	e := &NewAPIError{
+		Err:        err,
+		errorType:  ErrorTypeNewAPIError,
+		StatusCode: ErrorCodeClientClosedRequest.HTTPStatusCode(),
+		errorCode:  ErrorCodeClientClosedRequest,
+		skipRetry:  true,
+		Level:      ErrorCodeClientClosedRequest.DefaultLevel(), // Set default level
+	}
+	for _, op := range ops {
+		op(e)
+	}
+	return e
+}
+
+// IsClientClosedError reports whether the request failed because the client went away
+func IsClientClosedError(err *NewAPIError) bool {
+	return err != nil && err.errorCode == ErrorCodeClientClosedRequest
+}
+
+// isAbandonedRequest reports whether nobody was waiting for the answer any more,
+// so the failure says nothing about the health of the upstream
+func isAbandonedRequest(err *NewAPIError) bool {
+	return err.errorCode == ErrorCodeClientClosedRequest
+}
+
+// ShouldPenalizeChannel reports whether the error should count against the channel that
+// served the request, e.g. for automatic disabling. The decision comes from the policy of
+// the error code; failures caused by the client, including a closed connection, never count.
+func ShouldPenalizeChannel(err *NewAPIError) bool {
+	if err == nil || isAbandonedRequest(err) {
+		return false
+	}
+	return err.errorCode.PenalizesChannel()
+}
+
+// CountsTowardErrorRate reports whether the error should be counted in error-rate metrics.
+// Requests abandoned by the client are left out so a flaky client cannot skew the rate.
+func CountsTowardErrorRate(err *NewAPIError) bool {
+	return err != nil && !isAbandonedRequest(err)
+}
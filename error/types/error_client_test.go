@@ -0,0 +1,92 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestClassifyRequestError verifies a cancelled inbound context wins over the transport error
+func TestClassifyRequestError(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	e := ClassifyRequestError(canceled, context.Canceled)
+	if e.GetErrorCode() != ErrorCodeClientClosedRequest {
+		t.Errorf("ClassifyRequestError() errorCode = %d, want %d", e.GetErrorCode(), ErrorCodeClientClosedRequest)
+	}
+	if e.StatusCode != StatusClientClosedRequest {
+		t.Errorf("ClassifyRequestError() StatusCode = %d, want %d", e.StatusCode, StatusClientClosedRequest)
+	}
+	if e.Level != ErrorLevelInfo {
+		t.Errorf("ClassifyRequestError() Level = %v, want %v", e.Level, ErrorLevelInfo)
+	}
+	if !IsSkipRetryError(e) {
+		t.Errorf("ClassifyRequestError() skipRetry = false, want true")
+	}
+
+	// a transport error seen after the client left is still the client's doing
+	if e := ClassifyRequestError(canceled, errors.New("read: connection reset by peer")); !IsClientClosedError(e) {
+		t.Errorf("ClassifyRequestError() errorCode = %d, want %d", e.GetErrorCode(), ErrorCodeClientClosedRequest)
+	}
+
+	// a canceled upstream request gets the same code, so one event never has two
+	if e := ClassifyRequestError(context.Background(), context.Canceled); !IsClientClosedError(e) || !IsSkipRetryError(e) {
+		t.Errorf("ClassifyRequestError() errorCode = %d, want %d", e.GetErrorCode(), ErrorCodeClientClosedRequest)
+	}
+
+	if e := ClassifyRequestError(context.Background(), nil); e != nil {
+		t.Errorf("ClassifyRequestError(nil) = %v, want nil", e)
+	}
+}
+
+// TestShouldPenalizeChannel verifies only failures on the upstream side count against a channel
+func TestShouldPenalizeChannel(t *testing.T) {
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name             string
+		err              *NewAPIError
+		expectedPenalize bool
+		expectedCounted  bool
+	}{
+		{"Nil", nil, false, false},
+		{"Client closed", ClassifyRequestError(canceled, context.Canceled), false, false},
+		{"Upstream request canceled", ClassifyTransportError(context.Canceled), false, false},
+		{"Channel invalid key", NewError(errors.New("bad key"), ErrorCodeChannelInvalidKey), true, true},
+		{"Upstream timeout", NewError(context.DeadlineExceeded, ErrorCodeUpstreamTimeout), true, true},
+		{"Upstream overloaded", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeOverloaded}, StatusUpstreamOverloaded), true, true},
+		{"Upstream permission denied", WithOpenAIError(OpenAIError{Message: "model not enabled for this key", Type: "permission_error"}, http.StatusForbidden), true, true},
+		{"Upstream 403 without body", ParseUpstreamError(http.StatusForbidden, http.Header{}, nil), true, true},
+		{"Bad request body", NewError(errors.New("bad json"), ErrorCodeBadRequestBody), false, true},
+		{"Prompt blocked", NewError(errors.New("blocked"), ErrorCodePromptBlocked), false, true},
+		{"User quota", NewError(errors.New("no quota"), ErrorCodeInsufficientUserQuota), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShouldPenalizeChannel(tt.err); got != tt.expectedPenalize {
+				t.Errorf("ShouldPenalizeChannel() = %v, want %v", got, tt.expectedPenalize)
+			}
+			if got := CountsTowardErrorRate(tt.err); got != tt.expectedCounted {
+				t.Errorf("CountsTowardErrorRate() = %v, want %v", got, tt.expectedCounted)
+			}
+		})
+	}
+}
+
+// TestClientClosedRequestRendering verifies the 499 status survives every relay format
+func TestClientClosedRequestRendering(t *testing.T) {
+	e := NewError(context.Canceled, ErrorCodeClientClosedRequest)
+	if e.StatusCode != StatusClientClosedRequest {
+		t.Errorf("NewError() StatusCode = %d, want %d", e.StatusCode, StatusClientClosedRequest)
+	}
+	if result := e.ToGeminiError(); result.Status != GeminiStatusCancelled {
+		t.Errorf("ToGeminiError() Status = %q, want %q", result.Status, GeminiStatusCancelled)
+	}
+	if result := e.ToOpenAIError(); result.Code.String() != "client_closed_request" {
+		t.Errorf("ToOpenAIError() Code = %v, want %q", result.Code, "client_closed_request")
+	}
+}
@@ -3,6 +3,8 @@ package types
 import (
 	"fmt"
 	"net/http"
+
+	"github.com/QuantumNous/new-api/common"
 )
 
 // ErrorCode is a numeric error code for categorization and fast comparison
@@ -15,38 +17,33 @@ type ErrorCodeString string
 
 // String returns the string representation of the error code
 func (c ErrorCode) String() string {
-	return errorCodeStrings[c]
+	info, ok := errorRegistry[c]
+	if !ok {
+		return ""
+	}
+	return info.Name
 }
 
 // HTTPStatusCode returns the corresponding HTTP status code for the error
 func (c ErrorCode) HTTPStatusCode() int {
-	statusCode, ok := errorCodeHTTPStatusMap[c]
+	info, ok := errorRegistry[c]
 	if !ok {
 		return http.StatusInternalServerError
 	}
-	return statusCode
+	return info.HTTPStatus
 }
 
 // DefaultLevel returns the default error level for the error code
+// Unregistered codes take the level of their category
 func (c ErrorCode) DefaultLevel() ErrorLevel {
-	level, ok := errorCodeLevelMap[c]
-	if !ok {
-		return ErrorLevelError
+	if info, ok := errorRegistry[c]; ok && info.Level != nil {
+		return *info.Level
 	}
-	return level
+	return c.Category().Policy().Level
 }
 
 // Error code definitions
-// Numeric ranges:
-//   1xxx - General errors
-//   2xxx - System errors
-//   3xxx - Channel errors
-//   4xxx - Client errors
-//   5xxx - Upstream errors
-//   6xxx - Database errors
-//   7xxx - Quota errors
-//   8xxx - Authentication errors (reserved)
-//   9xxx - Miscellaneous errors (reserved)
+// The numeric ranges (1xxx general ... 9xxx misc) are defined by errorCategories in error_category.go
 
 const (
 	// General Errors (1xxx)
@@ -57,6 +54,7 @@ const (
 
 	// System Errors (2xxx)
 
+	ErrorCodeInternalError ErrorCode = 2000
 	ErrorCodeCountTokenFailed ErrorCode = 2001
 	ErrorCodeModelPriceError ErrorCode = 2002
 	ErrorCodeInvalidApiType ErrorCode = 2003
@@ -65,6 +63,12 @@ const (
 	ErrorCodeDoRequestFailed ErrorCode = 2006
 	ErrorCodeGetChannelFailed ErrorCode = 2007
 	ErrorCodeGenRelayInfoFailed ErrorCode = 2008
+	ErrorCodeUpstreamDNSFailed ErrorCode = 2009
+	ErrorCodeUpstreamConnectionRefused ErrorCode = 2010
+	ErrorCodeUpstreamTLSFailed ErrorCode = 2011
+	ErrorCodeUpstreamProxyFailed ErrorCode = 2012
+	ErrorCodeUpstreamTimeout ErrorCode = 2013
+	ErrorCodeUpstreamConnectionReset ErrorCode = 2014
 
 	// Channel Errors (3xxx)
 
@@ -76,6 +80,7 @@ const (
 	ErrorCodeChannelInvalidKey ErrorCode = 3006
 	ErrorCodeChannelResponseTimeExceeded ErrorCode = 3007
 	ErrorCodeChannelNotAvailable ErrorCode = 3008
+	ErrorCodeChannelPermissionDenied ErrorCode = 3009
 
 	// Client Errors (4xxx)
 
@@ -85,6 +90,7 @@ const (
 	ErrorCodeBadRequestBody ErrorCode = 4004
 	ErrorCodeUnauthorized ErrorCode = 4005
 	ErrorCodeForbidden ErrorCode = 4006
+	ErrorCodeClientClosedRequest ErrorCode = 4007
 
 	// Upstream Errors (5xxx)
 
@@ -98,6 +104,13 @@ const (
 	ErrorCodePromptBlocked ErrorCode = 5008
 	ErrorCodeRateLimitExceeded ErrorCode = 5009
 	ErrorCodeServiceUnavailable ErrorCode = 5010
+	ErrorCodeTaskNotFound ErrorCode = 5011
+	ErrorCodeTaskAlreadyExists ErrorCode = 5012
+	ErrorCodeUnknownUpstream ErrorCode = 5013
+	ErrorCodeUpstreamOverloaded ErrorCode = 5014
+	ErrorCodeUpstreamInsufficientQuota ErrorCode = 5015
+	ErrorCodeRequestTooLarge ErrorCode = 5016
+	ErrorCodeContextLengthExceeded ErrorCode = 5017
 
 	// Database Errors (6xxx)
 
@@ -112,202 +125,1020 @@ const (
 	ErrorCodeInsufficientUserQuota ErrorCode = 7001
 	ErrorCodePreConsumeTokenQuotaFailed ErrorCode = 7002
 	ErrorCodeQuotaExceeded ErrorCode = 7003
-)
-
-// errorCodeStrings maps error codes to their string representations
-var errorCodeStrings = map[ErrorCode]string{
-	// General Errors (1xxx)
-	ErrorCodeInvalidRequest:         "invalid_request",
-	ErrorCodeSensitiveWordsDetected: "sensitive_words_detected",
-	ErrorCodeViolationFeeGrokCSAM:   "violation_fee.grok_csam",
-
-	// System Errors (2xxx)
-	ErrorCodeCountTokenFailed:   "count_token_failed",
-	ErrorCodeModelPriceError:    "model_price_error",
-	ErrorCodeInvalidApiType:     "invalid_api_type",
-	ErrorCodeJsonMarshalFailed:  "json_marshal_failed",
-	ErrorCodeJsonUnmarshalFailed: "json_unmarshal_failed",
-	ErrorCodeDoRequestFailed:    "do_request_failed",
-	ErrorCodeGetChannelFailed:   "get_channel_failed",
-	ErrorCodeGenRelayInfoFailed: "gen_relay_info_failed",
-
-	// Channel Errors (3xxx)
-	ErrorCodeChannelNoAvailableKey:        "channel_no_available_key",
-	ErrorCodeChannelParamOverrideInvalid:  "channel_param_override_invalid",
-	ErrorCodeChannelHeaderOverrideInvalid: "channel_header_override_invalid",
-	ErrorCodeChannelModelMappedError:      "channel_model_mapped_error",
-	ErrorCodeChannelAwsClientError:        "channel_aws_client_error",
-	ErrorCodeChannelInvalidKey:            "channel_invalid_key",
-	ErrorCodeChannelResponseTimeExceeded:  "channel_response_time_exceeded",
-	ErrorCodeChannelNotAvailable:          "channel_not_available",
 
-	// Client Errors (4xxx)
-	ErrorCodeReadRequestBodyFailed: "read_request_body_failed",
-	ErrorCodeConvertRequestFailed:  "convert_request_failed",
-	ErrorCodeAccessDenied:          "access_denied",
-	ErrorCodeBadRequestBody:        "bad_request_body",
-	ErrorCodeUnauthorized:          "unauthorized",
-	ErrorCodeForbidden:             "forbidden",
-
-	// Upstream Errors (5xxx)
-	ErrorCodeReadResponseBodyFailed: "read_response_body_failed",
-	ErrorCodeBadResponseStatusCode:  "bad_response_status_code",
-	ErrorCodeBadResponse:            "bad_response",
-	ErrorCodeBadResponseBody:        "bad_response_body",
-	ErrorCodeEmptyResponse:          "empty_response",
-	ErrorCodeAwsInvokeError:         "aws_invoke_error",
-	ErrorCodeModelNotFound:          "model_not_found",
-	ErrorCodePromptBlocked:          "prompt_blocked",
-	ErrorCodeRateLimitExceeded:      "rate_limit_exceeded",
-	ErrorCodeServiceUnavailable:     "service_unavailable",
+	// Authentication Errors (8xxx)
 
-	// Database Errors (6xxx)
-	ErrorCodeQueryDataError:         "query_data_error",
-	ErrorCodeUpdateDataError:        "update_data_error",
-	ErrorCodeInsertDataError:        "insert_data_error",
-	ErrorCodeDeleteDataError:        "delete_data_error",
-	ErrorCodeDatabaseConnectionFailed: "database_connection_failed",
-
-	// Quota Errors (7xxx)
-	ErrorCodeInsufficientUserQuota:      "insufficient_user_quota",
-	ErrorCodePreConsumeTokenQuotaFailed: "pre_consume_token_quota_failed",
-	ErrorCodeQuotaExceeded:              "quota_exceeded",
-}
+	ErrorCodeTokenExpired ErrorCode = 8001
+	ErrorCodeTokenDisabled ErrorCode = 8002
+	ErrorCodeTokenNotFound ErrorCode = 8003
+	ErrorCodeTokenIPNotAllowed ErrorCode = 8004
+	ErrorCodeTokenModelNotPermitted ErrorCode = 8005
+	ErrorCodeTokenGroupNotPermitted ErrorCode = 8006
+	ErrorCodeUserBanned ErrorCode = 8007
+)
 
-// errorCodeHTTPStatusMap maps error codes to their HTTP status codes
-var errorCodeHTTPStatusMap = map[ErrorCode]int{
+// builtinErrors is the single source of truth for error code metadata
+// Every error code constant must have exactly one entry here
+var builtinErrors = []ErrorInfo{
 	// General Errors (1xxx)
-	ErrorCodeInvalidRequest:         http.StatusBadRequest,
-	ErrorCodeSensitiveWordsDetected: http.StatusBadRequest,
-	ErrorCodeViolationFeeGrokCSAM:   http.StatusBadRequest,
+	{
+		Code:       ErrorCodeInvalidRequest,
+		Name:       "invalid_request",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Invalid request parameters",
+			"zh": "请求参数无效",
+			"ja": "無効なリクエストパラメータ",
+			"fr": "Paramètres de requête invalides",
+			"ru": "Недействительные параметры запроса",
+			"vi": "Tham số yêu cầu không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeSensitiveWordsDetected,
+		Name:       "sensitive_words_detected",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Sensitive words detected in content",
+			"zh": "内容中检测到敏感词",
+			"ja": "コンテンツに敏感な単語が検出されました",
+			"fr": "Mots sensibles détectés dans le contenu",
+			"ru": "Обнаружены нежелательные слова в контенте",
+			"vi": "Phát hiện từ nhạy cảm trong nội dung",
+		},
+	},
+	{
+		Code:       ErrorCodeViolationFeeGrokCSAM,
+		Name:       "violation_fee.grok_csam",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Content policy violation detected",
+			"zh": "检测到内容违规",
+			"ja": "コンテンツポリシー違反が検出されました",
+			"fr": "Violation de la politique de contenu détectée",
+			"ru": "Обнаружено нарушение политики содержимого",
+			"vi": "Phát hiện vi phạm chính sách nội dung",
+		},
+	},
 
 	// System Errors (2xxx)
-	ErrorCodeCountTokenFailed:   http.StatusInternalServerError,
-	ErrorCodeModelPriceError:    http.StatusInternalServerError,
-	ErrorCodeInvalidApiType:     http.StatusBadRequest,
-	ErrorCodeJsonMarshalFailed:  http.StatusInternalServerError,
-	ErrorCodeJsonUnmarshalFailed: http.StatusInternalServerError,
-	ErrorCodeDoRequestFailed:    http.StatusInternalServerError,
-	ErrorCodeGetChannelFailed:   http.StatusInternalServerError,
-	ErrorCodeGenRelayInfoFailed: http.StatusInternalServerError,
+	{
+		Code:       ErrorCodeInternalError,
+		Name:       "internal_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Internal server error",
+			"zh": "服务器内部错误",
+			"ja": "内部サーバーエラー",
+			"fr": "Erreur interne du serveur",
+			"ru": "Внутренняя ошибка сервера",
+			"vi": "Lỗi máy chủ nội bộ",
+		},
+	},
+	{
+		Code:       ErrorCodeCountTokenFailed,
+		Name:       "count_token_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		// the reason, e.g. an unsupported content part, is what the caller needs to fix the request
+		ExposeMessage: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Failed to count tokens",
+			"zh": "Token 计数失败",
+			"ja": "トークン数のカウントに失敗しました",
+			"fr": "Échec du comptage des jetons",
+			"ru": "Не удалось подсчитать токены",
+			"vi": "Không thể đếm token",
+		},
+	},
+	{
+		Code:       ErrorCodeModelPriceError,
+		Name:       "model_price_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Model pricing configuration error",
+			"zh": "模型价格配置错误",
+			"ja": "モデル価格設定エラー",
+			"fr": "Erreur de configuration des prix du modèle",
+			"ru": "Ошибка конфигурации цены модели",
+			"vi": "Lỗi cấu hình giá mô hình",
+		},
+	},
+	{
+		Code:       ErrorCodeInvalidApiType,
+		Name:       "invalid_api_type",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Invalid API type",
+			"zh": "无效的 API 类型",
+			"ja": "無効なAPIタイプ",
+			"fr": "Type d'API invalide",
+			"ru": "Недействительный тип API",
+			"vi": "Loại API không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeJsonMarshalFailed,
+		Name:       "json_marshal_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Failed to marshal JSON",
+			"zh": "JSON 序列化失败",
+			"ja": "JSONマーシャリングに失敗しました",
+			"fr": "Échec du marshaling JSON",
+			"ru": "Не удалось упаковать JSON",
+			"vi": "Không thể chuyển đổi JSON",
+		},
+	},
+	{
+		Code:       ErrorCodeJsonUnmarshalFailed,
+		Name:       "json_unmarshal_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Failed to unmarshal JSON",
+			"zh": "JSON 反序列化失败",
+			"ja": "JSONアンマーシャリングに失敗しました",
+			"fr": "Échec de l'unmarshaling JSON",
+			"ru": "Не удалось распаковать JSON",
+			"vi": "Không thể phân tích JSON",
+		},
+	},
+	{
+		Code:       ErrorCodeDoRequestFailed,
+		Name:       "do_request_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Retry:      RetryDecisionOtherChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Failed to make HTTP request",
+			"zh": "HTTP 请求失败",
+			"ja": "HTTPリクエストが失敗しました",
+			"fr": "Échec de la requête HTTP",
+			"ru": "Не выполнить HTTP-запрос",
+			"vi": "Yêu cầu HTTP không thành công",
+		},
+	},
+	{
+		Code:       ErrorCodeGetChannelFailed,
+		Name:       "get_channel_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Failed to get channel information",
+			"zh": "获取渠道信息失败",
+			"ja": "チャンネル情報の取得に失敗しました",
+			"fr": "Échec de la récupération des informations du canal",
+			"ru": "Не удалось получить информацию о канале",
+			"vi": "Không thể lấy thông tin kênh",
+		},
+	},
+	{
+		Code:       ErrorCodeGenRelayInfoFailed,
+		Name:       "gen_relay_info_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Failed to generate relay information",
+			"zh": "生成中继信息失败",
+			"ja": "リレー情報の生成に失敗しました",
+			"fr": "Échec de la génération des informations de relais",
+			"ru": "Не удалось создать информацию о ретрансляции",
+			"vi": "Không thể tạo thông tin tiếprelay",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamDNSFailed,
+		Name:       "upstream_dns_failed",
+		HTTPStatus: http.StatusBadGateway,
+		Retry:      RetryDecisionOtherChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Failed to resolve upstream host",
+			"zh": "上游主机域名解析失败",
+			"ja": "上流ホストの名前解決に失敗しました",
+			"fr": "Échec de la résolution de l'hôte en amont",
+			"ru": "Не удалось разрешить имя вышестоящего хоста",
+			"vi": "Không thể phân giải tên máy chủ thượng nguồn",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamConnectionRefused,
+		Name:       "upstream_connection_refused",
+		HTTPStatus: http.StatusBadGateway,
+		Retry:      RetryDecisionOtherChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Upstream refused the connection",
+			"zh": "上游拒绝连接",
+			"ja": "上流サービスが接続を拒否しました",
+			"fr": "Le service en amont a refusé la connexion",
+			"ru": "Вышестоящий сервис отклонил соединение",
+			"vi": "Dịch vụ thượng nguồn từ chối kết nối",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamTLSFailed,
+		Name:       "upstream_tls_failed",
+		HTTPStatus: http.StatusBadGateway,
+		Retry:      RetryDecisionOtherChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "TLS handshake with upstream failed",
+			"zh": "与上游的 TLS 握手失败",
+			"ja": "上流とのTLSハンドシェイクに失敗しました",
+			"fr": "Échec de la négociation TLS avec le service en amont",
+			"ru": "Не удалось установить TLS-соединение с вышестоящим сервисом",
+			"vi": "Bắt tay TLS với dịch vụ thượng nguồn thất bại",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamProxyFailed,
+		Name:       "upstream_proxy_failed",
+		HTTPStatus: http.StatusBadGateway,
+		Retry:      RetryDecisionOtherChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Failed to connect through proxy",
+			"zh": "通过代理连接失败",
+			"ja": "プロキシ経由の接続に失敗しました",
+			"fr": "Échec de la connexion via le proxy",
+			"ru": "Не удалось подключиться через прокси",
+			"vi": "Không thể kết nối qua proxy",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamTimeout,
+		Name:       "upstream_timeout",
+		HTTPStatus: http.StatusGatewayTimeout,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionOtherChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Upstream request timed out",
+			"zh": "上游请求超时",
+			"ja": "上流リクエストがタイムアウトしました",
+			"fr": "La requête en amont a expiré",
+			"ru": "Истекло время ожидания вышестоящего запроса",
+			"vi": "Yêu cầu thượng nguồn đã hết thời gian chờ",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamConnectionReset,
+		Name:       "upstream_connection_reset",
+		HTTPStatus: http.StatusBadGateway,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionSameChannel,
+		PenalizeChannel: common.GetPointer(true),
+		Messages: ErrorMessage{
+			"en": "Upstream connection was reset",
+			"zh": "上游连接被重置",
+			"ja": "上流との接続がリセットされました",
+			"fr": "La connexion en amont a été réinitialisée",
+			"ru": "Соединение с вышестоящим сервисом было сброшено",
+			"vi": "Kết nối thượng nguồn đã bị đặt lại",
+		},
+	},
 
 	// Channel Errors (3xxx)
-	ErrorCodeChannelNoAvailableKey:        http.StatusServiceUnavailable,
-	ErrorCodeChannelParamOverrideInvalid:  http.StatusBadRequest,
-	ErrorCodeChannelHeaderOverrideInvalid: http.StatusBadRequest,
-	ErrorCodeChannelModelMappedError:      http.StatusInternalServerError,
-	ErrorCodeChannelAwsClientError:        http.StatusInternalServerError,
-	ErrorCodeChannelInvalidKey:            http.StatusUnauthorized,
-	ErrorCodeChannelResponseTimeExceeded:  http.StatusGatewayTimeout,
-	ErrorCodeChannelNotAvailable:          http.StatusServiceUnavailable,
+	{
+		Code:       ErrorCodeChannelNoAvailableKey,
+		Name:       "channel_no_available_key",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Messages: ErrorMessage{
+			"en": "No available API key in channel",
+			"zh": "渠道中没有可用的 API 密钥",
+			"ja": "チャンネルに利用可能なAPIキーがありません",
+			"fr": "Aucune clé API disponible dans le canal",
+			"ru": "Нет доступного ключа API в канале",
+			"vi": "Không có khóa API khả dụng trong kênh",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelParamOverrideInvalid,
+		Name:       "channel_param_override_invalid",
+		HTTPStatus: http.StatusBadRequest,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Invalid channel parameter override",
+			"zh": "无效的渠道参数覆盖",
+			"ja": "無効なチャンネルパラメータオーバーライド",
+			"fr": "Remplacement de paramètre de canal invalide",
+			"ru": "Недействительное пер��определение параметра канала",
+			"vi": "Ghi đè tham số kênh không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelHeaderOverrideInvalid,
+		Name:       "channel_header_override_invalid",
+		HTTPStatus: http.StatusBadRequest,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Invalid channel header override",
+			"zh": "无效的渠道请求头覆盖",
+			"ja": "無効なチャンネルヘッダーオーバーライド",
+			"fr": "Remplacement d'en-tête de canal invalide",
+			"ru": "Недействительное переопределение заголовка канала",
+			"vi": "Ghi đè tiêu đề kênh không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelModelMappedError,
+		Name:       "channel_model_mapped_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Channel model mapping error",
+			"zh": "渠道模型映射错误",
+			"ja": "チャンネルモデルマッピングエラー",
+			"fr": "Erreur de mappage de modèle de canal",
+			"ru": "Ошибка сопоставления модели канала",
+			"vi": "Lỗi ánh xạ mô hình kênh",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelAwsClientError,
+		Name:       "channel_aws_client_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "AWS client configuration error",
+			"zh": "AWS 客户端配置错误",
+			"ja": "AWSクライアント設定エラー",
+			"fr": "Erreur de configuration du client AWS",
+			"ru": "Ошибка конфигурации клиента AWS",
+			"vi": "Lỗi cấu hình client AWS",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelInvalidKey,
+		Name:       "channel_invalid_key",
+		HTTPStatus: http.StatusUnauthorized,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Invalid channel API key",
+			"zh": "无效的渠道 API 密钥",
+			"ja": "無効なチャンネルAPIキー",
+			"fr": "Clé API de canal invalide",
+			"ru": "Недействительный ключ API канала",
+			"vi": "Khóa API kênh không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelResponseTimeExceeded,
+		Name:       "channel_response_time_exceeded",
+		HTTPStatus: http.StatusGatewayTimeout,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Channel response time exceeded",
+			"zh": "渠道响应时间超限",
+			"ja": "チャンネル応答時間超過",
+			"fr": "Temps de réponse du canal dépassé",
+			"ru": "Превышено время ответа канала",
+			"vi": "Thời gian phản hồi kênh vượt quá giới hạn",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelNotAvailable,
+		Name:       "channel_not_available",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Channel is not available",
+			"zh": "渠道不可用",
+			"ja": "チャンネルが利用できません",
+			"fr": "Le canal n'est pas disponible",
+			"ru": "Канал недоступен",
+			"vi": "Kênh không khả dụng",
+		},
+	},
+	{
+		Code:       ErrorCodeChannelPermissionDenied,
+		Name:       "channel_permission_denied",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Messages: ErrorMessage{
+			"en": "Channel API key is not permitted to perform this request",
+			"zh": "渠道 API 密钥无权执行此请求",
+			"ja": "チャンネルAPIキーにはこのリクエストを実行する権限がありません",
+			"fr": "La clé API du canal n'est pas autorisée à effectuer cette requête",
+			"ru": "Ключ API канала не имеет разрешения на выполнение этого запроса",
+			"vi": "Khóa API kênh không có quyền thực hiện yêu cầu này",
+		},
+	},
 
 	// Client Errors (4xxx)
-	ErrorCodeReadRequestBodyFailed: http.StatusBadRequest,
-	ErrorCodeConvertRequestFailed:  http.StatusBadRequest,
-	ErrorCodeAccessDenied:          http.StatusUnauthorized,
-	ErrorCodeBadRequestBody:        http.StatusBadRequest,
-	ErrorCodeUnauthorized:          http.StatusUnauthorized,
-	ErrorCodeForbidden:             http.StatusForbidden,
+	{
+		Code:       ErrorCodeReadRequestBodyFailed,
+		Name:       "read_request_body_failed",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Failed to read request body",
+			"zh": "读取请求体失败",
+			"ja": "リクエストボディの読み取りに失敗しました",
+			"fr": "Échec de la lecture du corps de la requête",
+			"ru": "Не удалось прочитать тело запроса",
+			"vi": "Không thể đọc nội dung yêu cầu",
+		},
+	},
+	{
+		Code:       ErrorCodeConvertRequestFailed,
+		Name:       "convert_request_failed",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Failed to convert request format",
+			"zh": "转换请求格式失败",
+			"ja": "リクエストフォーマットの変換に失敗しました",
+			"fr": "Échec de la conversion du format de requête",
+			"ru": "Не удалось преобразовать формат запроса",
+			"vi": "Không thể chuyển đổi định dạng yêu cầu",
+		},
+	},
+	{
+		Code:       ErrorCodeAccessDenied,
+		Name:       "access_denied",
+		HTTPStatus: http.StatusUnauthorized,
+		Messages: ErrorMessage{
+			"en": "Access denied",
+			"zh": "访问被拒绝",
+			"ja": "アクセス拒否",
+			"fr": "Accès refusé",
+			"ru": "Доступ запрещен",
+			"vi": "Quyền truy cập bị từ chối",
+		},
+	},
+	{
+		Code:       ErrorCodeBadRequestBody,
+		Name:       "bad_request_body",
+		HTTPStatus: http.StatusBadRequest,
+		Messages: ErrorMessage{
+			"en": "Invalid request body",
+			"zh": "无效的请求体",
+			"ja": "無効なリクエストボディ",
+			"fr": "Corps de requête invalide",
+			"ru": "Недействительное тело запроса",
+			"vi": "Nội dung yêu cầu không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeUnauthorized,
+		Name:       "unauthorized",
+		HTTPStatus: http.StatusUnauthorized,
+		Messages: ErrorMessage{
+			"en": "Unauthorized access",
+			"zh": "未授权访问",
+			"ja": "不正アクセス",
+			"fr": "Accès non autorisé",
+			"ru": "Неавторизованный доступ",
+			"vi": "Truy cập trái phép",
+		},
+	},
+	{
+		Code:       ErrorCodeForbidden,
+		Name:       "forbidden",
+		HTTPStatus: http.StatusForbidden,
+		Messages: ErrorMessage{
+			"en": "Forbidden",
+			"zh": "禁止访问",
+			"ja": "アクセス禁止",
+			"fr": "Interdit",
+			"ru": "Запрещено",
+			"vi": "Bị cấm",
+		},
+	},
+	{
+		Code:       ErrorCodeClientClosedRequest,
+		Name:       "client_closed_request",
+		HTTPStatus: StatusClientClosedRequest,
+		Level:      common.GetPointer(ErrorLevelInfo),
+		Messages: ErrorMessage{
+			"en": "Client closed the request",
+			"zh": "客户端已关闭请求",
+			"ja": "クライアントがリクエストを閉じました",
+			"fr": "Le client a fermé la requête",
+			"ru": "Клиент закрыл запрос",
+			"vi": "Máy khách đã đóng yêu cầu",
+		},
+	},
 
 	// Upstream Errors (5xxx)
-	ErrorCodeReadResponseBodyFailed: http.StatusInternalServerError,
-	ErrorCodeBadResponseStatusCode:  http.StatusBadGateway,
-	ErrorCodeBadResponse:            http.StatusBadGateway,
-	ErrorCodeBadResponseBody:        http.StatusInternalServerError,
-	ErrorCodeEmptyResponse:          http.StatusInternalServerError,
-	ErrorCodeAwsInvokeError:         http.StatusInternalServerError,
-	ErrorCodeModelNotFound:          http.StatusNotFound,
-	ErrorCodePromptBlocked:          http.StatusBadRequest,
-	ErrorCodeRateLimitExceeded:      http.StatusTooManyRequests,
-	ErrorCodeServiceUnavailable:     http.StatusServiceUnavailable,
+	{
+		Code:       ErrorCodeReadResponseBodyFailed,
+		Name:       "read_response_body_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Retry:      RetryDecisionSameChannel,
+		Messages: ErrorMessage{
+			"en": "Failed to read response body",
+			"zh": "读取响应体失败",
+			"ja": "レスポンスボディの読み取りに失敗しました",
+			"fr": "Échec de la lecture du corps de la réponse",
+			"ru": "Не удалось прочитать тело ответа",
+			"vi": "Không thể đọc nội dung phản hồi",
+		},
+	},
+	{
+		Code:       ErrorCodeBadResponseStatusCode,
+		Name:       "bad_response_status_code",
+		HTTPStatus: http.StatusBadGateway,
+		Messages: ErrorMessage{
+			"en": "Bad response status code from upstream",
+			"zh": "上游返回错误的状态码",
+			"ja": "アップストリームから不正なステータスコードが返されました",
+			"fr": "Mauvais code de statut de réponse de l'amont",
+			"ru": "Плохой код статуса ответа от восходящего потока",
+			"vi": "Mã trạng thái phản hồi không hợp lệ từ phía thượng nguồn",
+		},
+	},
+	{
+		Code:       ErrorCodeBadResponse,
+		Name:       "bad_response",
+		HTTPStatus: http.StatusBadGateway,
+		Messages: ErrorMessage{
+			"en": "Bad response from upstream service",
+			"zh": "上游服务返回错误响应",
+			"ja": "アップストリームサービスから不正な応答がありました",
+			"fr": "Mauvaise réponse du service en amont",
+			"ru": "Плохой ответ от вышестоящего сервиса",
+			"vi": "Phản hồi không hợp lệ từ dịch vụ thượng nguồn",
+		},
+	},
+	{
+		Code:       ErrorCodeBadResponseBody,
+		Name:       "bad_response_body",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "Invalid response body format",
+			"zh": "无效的响应体格式",
+			"ja": "無効なレスポンスボディフォーマット",
+			"fr": "Format de corps de réponse invalide",
+			"ru": "Недействительный формат тела ответа",
+			"vi": "Định dạng nội dung phản hồi không hợp lệ",
+		},
+	},
+	{
+		Code:       ErrorCodeEmptyResponse,
+		Name:       "empty_response",
+		HTTPStatus: http.StatusInternalServerError,
+		Retry:      RetryDecisionSameChannel,
+		Messages: ErrorMessage{
+			"en": "Empty response from upstream",
+			"zh": "上游返回空响应",
+			"ja": "アップストリームからの空の応答",
+			"fr": "Réponse vide de l'amont",
+			"ru": "Пустой ответ от восходящего потока",
+			"vi": "Phản hồi trống từ thượng nguồn",
+		},
+	},
+	{
+		Code:       ErrorCodeAwsInvokeError,
+		Name:       "aws_invoke_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Messages: ErrorMessage{
+			"en": "AWS invocation error",
+			"zh": "AWS 调用错误",
+			"ja": "AWS呼び出しエラー",
+			"fr": "Erreur d'invocation AWS",
+			"ru": "Ошибка вызова AWS",
+			"vi": "Lỗi gọi AWS",
+		},
+	},
+	{
+		Code:       ErrorCodeModelNotFound,
+		Name:       "model_not_found",
+		HTTPStatus: http.StatusNotFound,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Model not found",
+			"zh": "未找到模型",
+			"ja": "モデルが見つかりません",
+			"fr": "Modèle introuvable",
+			"ru": "Модель не найдена",
+			"vi": "Không tìm thấy mô hình",
+		},
+	},
+	{
+		Code:       ErrorCodePromptBlocked,
+		Name:       "prompt_blocked",
+		HTTPStatus: http.StatusBadRequest,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionNone,
+		PenalizeChannel: common.GetPointer(false),
+		Messages: ErrorMessage{
+			"en": "Prompt blocked by content filter",
+			"zh": "提示词被内容过滤器阻止",
+			"ja": "プロンプトがコンテンツフィルターによってブロックされました",
+			"fr": "Invite bloquée par le filtre de contenu",
+			"ru": "Подсказка заблокирована контентным фильтром",
+			"vi": "Lỗi bị bộ lọc nội dung chặn",
+		},
+	},
+	{
+		Code:       ErrorCodeRateLimitExceeded,
+		Name:       "rate_limit_exceeded",
+		HTTPStatus: http.StatusTooManyRequests,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Rate limit exceeded",
+			"zh": "超过速率限制",
+			"ja": "レート制限を超過しました",
+			"fr": "Limite de taux dépassée",
+			"ru": "Превышен лимит скорости",
+			"vi": "Vượt quá giới hạn tốc độ",
+		},
+	},
+	{
+		Code:       ErrorCodeServiceUnavailable,
+		Name:       "service_unavailable",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Service temporarily unavailable",
+			"zh": "服务暂时不可用",
+			"ja": "サービスは一時的に利用できません",
+			"fr": "Service temporairement indisponible",
+			"ru": "Сервис временно недоступен",
+			"vi": "Dịch vụ tạm thời không khả dụng",
+		},
+	},
+	{
+		Code:       ErrorCodeTaskNotFound,
+		Name:       "task_not_found",
+		HTTPStatus: http.StatusNotFound,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionNone,
+		PenalizeChannel: common.GetPointer(false),
+		Messages: ErrorMessage{
+			"en": "Task not found",
+			"zh": "任务不存在",
+			"ja": "タスクが見つかりません",
+			"fr": "Tâche introuvable",
+			"ru": "Задача не найдена",
+			"vi": "Không tìm thấy tác vụ",
+		},
+	},
+	{
+		Code:       ErrorCodeTaskAlreadyExists,
+		Name:       "task_already_exists",
+		HTTPStatus: http.StatusConflict,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionNone,
+		PenalizeChannel: common.GetPointer(false),
+		Messages: ErrorMessage{
+			"en": "Task already exists",
+			"zh": "任务已存在",
+			"ja": "タスクは既に存在します",
+			"fr": "La tâche existe déjà",
+			"ru": "Задача уже существует",
+			"vi": "Tác vụ đã tồn tại",
+		},
+	},
+	{
+		Code:       ErrorCodeUnknownUpstream,
+		Name:       "unknown_upstream_error",
+		HTTPStatus: http.StatusBadGateway,
+		Messages: ErrorMessage{
+			"en": "Unrecognized error from upstream service",
+			"zh": "上游服务返回了无法识别的错误",
+			"ja": "上流サービスから認識できないエラーが返されました",
+			"fr": "Erreur non reconnue du service en amont",
+			"ru": "Нераспознанная ошибка от вышестоящего сервиса",
+			"vi": "Lỗi không xác định từ dịch vụ thượng nguồn",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamOverloaded,
+		Name:       "upstream_overloaded",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Messages: ErrorMessage{
+			"en": "Upstream service is overloaded",
+			"zh": "上游服务过载",
+			"ja": "上流サービスが過負荷状態です",
+			"fr": "Le service en amont est surchargé",
+			"ru": "Вышестоящий сервис перегружен",
+			"vi": "Dịch vụ thượng nguồn bị quá tải",
+		},
+	},
+	{
+		Code:       ErrorCodeUpstreamInsufficientQuota,
+		Name:       "upstream_insufficient_quota",
+		HTTPStatus: http.StatusServiceUnavailable,
+		Messages: ErrorMessage{
+			"en": "Upstream account has insufficient quota or billing issue",
+			"zh": "上游账户额度不足或计费异常",
+			"ja": "上流アカウントのクォータ不足または請求の問題",
+			"fr": "Quota insuffisant ou problème de facturation du compte en amont",
+			"ru": "Недостаточная квота или проблема с оплатой у вышестоящего аккаунта",
+			"vi": "Tài khoản thượng nguồn không đủ hạn ngạch hoặc có vấn đề thanh toán",
+		},
+	},
+	{
+		Code:       ErrorCodeRequestTooLarge,
+		Name:       "request_too_large",
+		HTTPStatus: http.StatusRequestEntityTooLarge,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionNone,
+		PenalizeChannel: common.GetPointer(false),
+		Messages: ErrorMessage{
+			"en": "Request exceeds the maximum allowed size",
+			"zh": "请求超过允许的最大大小",
+			"ja": "リクエストが許可された最大サイズを超えています",
+			"fr": "La requête dépasse la taille maximale autorisée",
+			"ru": "Запрос превышает максимально допустимый размер",
+			"vi": "Yêu cầu vượt quá kích thước tối đa cho phép",
+		},
+	},
+	{
+		Code:       ErrorCodeContextLengthExceeded,
+		Name:       "context_length_exceeded",
+		HTTPStatus: http.StatusBadRequest,
+		Level:      common.GetPointer(ErrorLevelWarning),
+		Retry:      RetryDecisionNone,
+		PenalizeChannel: common.GetPointer(false),
+		Messages: ErrorMessage{
+			"en": "Input exceeds the model's context length",
+			"zh": "输入超出模型的上下文长度",
+			"ja": "入力がモデルのコンテキスト長を超えています",
+			"fr": "L'entrée dépasse la longueur de contexte du modèle",
+			"ru": "Входные данные превышают длину контекста модели",
+			"vi": "Đầu vào vượt quá độ dài ngữ cảnh của mô hình",
+		},
+	},
 
 	// Database Errors (6xxx)
-	ErrorCodeQueryDataError:         http.StatusInternalServerError,
-	ErrorCodeUpdateDataError:        http.StatusInternalServerError,
-	ErrorCodeInsertDataError:        http.StatusInternalServerError,
-	ErrorCodeDeleteDataError:        http.StatusInternalServerError,
-	ErrorCodeDatabaseConnectionFailed: http.StatusInternalServerError,
+	{
+		Code:       ErrorCodeQueryDataError,
+		Name:       "query_data_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Database query error",
+			"zh": "数据库查询错误",
+			"ja": "データベースクエリエラー",
+			"fr": "Erreur de requête de base de données",
+			"ru": "Ошибка запроса к базе данных",
+			"vi": "Lỗi truy vấn cơ sở dữ liệu",
+		},
+	},
+	{
+		Code:       ErrorCodeUpdateDataError,
+		Name:       "update_data_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Database update error",
+			"zh": "数据库更新错误",
+			"ja": "データベース更新エラー",
+			"fr": "Erreur de mise à jour de la base de données",
+			"ru": "Ошибка обновления базы данных",
+			"vi": "Lỗi cập nhật cơ sở dữ liệu",
+		},
+	},
+	{
+		Code:       ErrorCodeInsertDataError,
+		Name:       "insert_data_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Database insert error",
+			"zh": "数据库插入错误",
+			"ja": "データベース挿入エラー",
+			"fr": "Erreur d'insertion dans la base de données",
+			"ru": "Ошибка вставки в базу данных",
+			"vi": "Lỗi chèn cơ sở dữ liệu",
+		},
+	},
+	{
+		Code:       ErrorCodeDeleteDataError,
+		Name:       "delete_data_error",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Database delete error",
+			"zh": "数据库删除错误",
+			"ja": "データベース削除エラー",
+			"fr": "Erreur de suppression de la base de données",
+			"ru": "Ошибка удаления из базы данных",
+			"vi": "Lỗi xóa cơ sở dữ liệu",
+		},
+	},
+	{
+		Code:       ErrorCodeDatabaseConnectionFailed,
+		Name:       "database_connection_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelCritical),
+		Messages: ErrorMessage{
+			"en": "Database connection failed",
+			"zh": "数据库连接失败",
+			"ja": "データベース接続に失敗しました",
+			"fr": "Échec de la connexion à la base de données",
+			"ru": "Не удалось подключиться к базе данных",
+			"vi": "Không thể kết nối cơ sở dữ liệu",
+		},
+	},
 
 	// Quota Errors (7xxx)
-	ErrorCodeInsufficientUserQuota:      http.StatusPaymentRequired,
-	ErrorCodePreConsumeTokenQuotaFailed: http.StatusInternalServerError,
-	ErrorCodeQuotaExceeded:              http.StatusPaymentRequired,
-}
-
-// errorCodeLevelMap maps error codes to their default severity levels
-var errorCodeLevelMap = map[ErrorCode]ErrorLevel{
-	// General Errors (1xxx)
-	ErrorCodeInvalidRequest:         ErrorLevelWarning,
-	ErrorCodeSensitiveWordsDetected: ErrorLevelWarning,
-	ErrorCodeViolationFeeGrokCSAM:   ErrorLevelWarning,
+	{
+		Code:       ErrorCodeInsufficientUserQuota,
+		Name:       "insufficient_user_quota",
+		HTTPStatus: http.StatusPaymentRequired,
+		Messages: ErrorMessage{
+			"en": "Insufficient user quota",
+			"zh": "用户配额不足",
+			"ja": "ユーザークォータが不足しています",
+			"fr": "Quota utilisateur insuffisant",
+			"ru": "Недостаточная квота пользователя",
+			"vi": "Hạn ngạch người dùng không đủ",
+		},
+	},
+	{
+		Code:       ErrorCodePreConsumeTokenQuotaFailed,
+		Name:       "pre_consume_token_quota_failed",
+		HTTPStatus: http.StatusInternalServerError,
+		Level:      common.GetPointer(ErrorLevelError),
+		Messages: ErrorMessage{
+			"en": "Failed to pre-consume token quota",
+			"zh": "预消耗 token 配额失败",
+			"ja": "トークンクォータの事前消費に失敗しました",
+			"fr": "Échec de la pré-consommation du quota de jetons",
+			"ru": "Не удалось предварительно израсходовать квоту токенов",
+			"vi": "Không thể tiêu thụ hạn ngạch token trước",
+		},
+	},
+	{
+		Code:       ErrorCodeQuotaExceeded,
+		Name:       "quota_exceeded",
+		HTTPStatus: http.StatusPaymentRequired,
+		Messages: ErrorMessage{
+			"en": "User quota exceeded",
+			"zh": "超出用户配额",
+			"ja": "ユーザークォータを超過しました",
+			"fr": "Quota utilisateur dépassé",
+			"ru": "Превышена квота пользователя",
+			"vi": "Vượt quá hạn ngạch người dùng",
+		},
+	},
 
-	// System Errors (2xxx)
-	ErrorCodeCountTokenFailed:   ErrorLevelError,
-	ErrorCodeModelPriceError:    ErrorLevelError,
-	ErrorCodeInvalidApiType:     ErrorLevelError,
-	ErrorCodeJsonMarshalFailed:  ErrorLevelError,
-	ErrorCodeJsonUnmarshalFailed: ErrorLevelError,
-	ErrorCodeDoRequestFailed:    ErrorLevelError,
-	ErrorCodeGetChannelFailed:   ErrorLevelCritical,
-	ErrorCodeGenRelayInfoFailed: ErrorLevelError,
-
-	// Channel Errors (3xxx)
-	ErrorCodeChannelNoAvailableKey:        ErrorLevelError,
-	ErrorCodeChannelParamOverrideInvalid:  ErrorLevelWarning,
-	ErrorCodeChannelHeaderOverrideInvalid: ErrorLevelWarning,
-	ErrorCodeChannelModelMappedError:      ErrorLevelError,
-	ErrorCodeChannelAwsClientError:        ErrorLevelError,
-	ErrorCodeChannelInvalidKey:            ErrorLevelWarning,
-	ErrorCodeChannelResponseTimeExceeded:  ErrorLevelWarning,
-	ErrorCodeChannelNotAvailable:          ErrorLevelCritical,
-
-	// Client Errors (4xxx)
-	ErrorCodeReadRequestBodyFailed: ErrorLevelWarning,
-	ErrorCodeConvertRequestFailed:  ErrorLevelWarning,
-	ErrorCodeAccessDenied:          ErrorLevelWarning,
-	ErrorCodeBadRequestBody:        ErrorLevelWarning,
-	ErrorCodeUnauthorized:          ErrorLevelWarning,
-	ErrorCodeForbidden:             ErrorLevelWarning,
+	// Authentication Errors (8xxx)
+	{
+		Code:       ErrorCodeTokenExpired,
+		Name:       "token_expired",
+		HTTPStatus: http.StatusUnauthorized,
+		Messages: ErrorMessage{
+			"en": "Token has expired",
+			"zh": "令牌已过期",
+			"ja": "トークンの有効期限が切れています",
+			"fr": "Le jeton a expiré",
+			"ru": "Срок действия токена истёк",
+			"vi": "Token đã hết hạn",
+		},
+	},
+	{
+		Code:       ErrorCodeTokenDisabled,
+		Name:       "token_disabled",
+		HTTPStatus: http.StatusUnauthorized,
+		Messages: ErrorMessage{
+			"en": "Token has been disabled",
+			"zh": "令牌已被禁用",
+			"ja": "トークンは無効化されています",
+			"fr": "Le jeton a été désactivé",
+			"ru": "Токен отключён",
+			"vi": "Token đã bị vô hiệu hóa",
+		},
+	},
+	{
+		Code:       ErrorCodeTokenNotFound,
+		Name:       "token_not_found",
+		HTTPStatus: http.StatusUnauthorized,
+		Messages: ErrorMessage{
+			"en": "Token not found",
+			"zh": "令牌不存在",
+			"ja": "トークンが見つかりません",
+			"fr": "Jeton introuvable",
+			"ru": "Токен не найден",
+			"vi": "Không tìm thấy token",
+		},
+	},
+	{
+		Code:       ErrorCodeTokenIPNotAllowed,
+		Name:       "token_ip_not_allowed",
+		HTTPStatus: http.StatusForbidden,
+		Messages: ErrorMessage{
+			"en": "Your IP address is not allowed to use this token",
+			"zh": "您的 IP 地址不在该令牌的允许列表中",
+			"ja": "このIPアドレスではこのトークンを使用できません",
+			"fr": "Votre adresse IP n'est pas autorisée à utiliser ce jeton",
+			"ru": "Вашему IP-адресу не разрешено использовать этот токен",
+			"vi": "Địa chỉ IP của bạn không được phép sử dụng token này",
+		},
+	},
+	{
+		Code:       ErrorCodeTokenModelNotPermitted,
+		Name:       "token_model_not_permitted",
+		HTTPStatus: http.StatusForbidden,
+		Messages: ErrorMessage{
+			"en": "This token is not permitted to use the requested model",
+			"zh": "该令牌无权使用所请求的模型",
+			"ja": "このトークンは要求されたモデルを使用できません",
+			"fr": "Ce jeton n'est pas autorisé à utiliser le modèle demandé",
+			"ru": "Этому токену не разрешено использовать запрошенную модель",
+			"vi": "Token này không được phép sử dụng mô hình được yêu cầu",
+		},
+	},
+	{
+		Code:       ErrorCodeTokenGroupNotPermitted,
+		Name:       "token_group_not_permitted",
+		HTTPStatus: http.StatusForbidden,
+		Messages: ErrorMessage{
+			"en": "This token is not permitted to use the requested group",
+			"zh": "该令牌无权使用所请求的分组",
+			"ja": "このトークンは要求されたグループを使用できません",
+			"fr": "Ce jeton n'est pas autorisé à utiliser le groupe demandé",
+			"ru": "Этому токену не разрешено использовать запрошенную группу",
+			"vi": "Token này không được phép sử dụng nhóm được yêu cầu",
+		},
+	},
+	{
+		Code:       ErrorCodeUserBanned,
+		Name:       "user_banned",
+		HTTPStatus: http.StatusForbidden,
+		Messages: ErrorMessage{
+			"en": "User has been banned",
+			"zh": "用户已被封禁",
+			"ja": "ユーザーは利用停止されています",
+			"fr": "L'utilisateur a été banni",
+			"ru": "Пользователь заблокирован",
+			"vi": "Người dùng đã bị cấm",
+		},
+	},
+}
 
-	// Upstream Errors (5xxx)
-	ErrorCodeReadResponseBodyFailed: ErrorLevelError,
-	ErrorCodeBadResponseStatusCode:  ErrorLevelError,
-	ErrorCodeBadResponse:            ErrorLevelError,
-	ErrorCodeBadResponseBody:        ErrorLevelError,
-	ErrorCodeEmptyResponse:          ErrorLevelError,
-	ErrorCodeAwsInvokeError:         ErrorLevelError,
-	ErrorCodeModelNotFound:          ErrorLevelWarning,
-	ErrorCodePromptBlocked:          ErrorLevelWarning,
-	ErrorCodeRateLimitExceeded:      ErrorLevelWarning,
-	ErrorCodeServiceUnavailable:     ErrorLevelCritical,
+// ErrorCodeFromString converts a string representation to an ErrorCode
+// Returns ErrorCodeUnknownUpstream if not found, so unrecognized upstream codes
+// are never reported as client mistakes
+func ErrorCodeFromString(s string) ErrorCode {
+	code, ok := errorRegistryByName[s]
+	if !ok {
+		return ErrorCodeUnknownUpstream
+	}
+	return code
+}
 
-	// Database Errors (6xxx)
-	ErrorCodeQueryDataError:         ErrorLevelCritical,
-	ErrorCodeUpdateDataError:        ErrorLevelCritical,
-	ErrorCodeInsertDataError:        ErrorLevelCritical,
-	ErrorCodeDeleteDataError:        ErrorLevelCritical,
-	ErrorCodeDatabaseConnectionFailed: ErrorLevelCritical,
+// errorCodeFromOwnName looks up one of our own code names in an upstream error body;
+// another gateway in front of us may already speak our own code names
+func errorCodeFromOwnName(name string) (ErrorCode, bool) {
+	code, ok := errorRegistryByName[name]
+	return code, ok
+}
 
-	// Quota Errors (7xxx)
-	ErrorCodeInsufficientUserQuota:      ErrorLevelWarning,
-	ErrorCodePreConsumeTokenQuotaFailed: ErrorLevelError,
-	ErrorCodeQuotaExceeded:              ErrorLevelWarning,
+// isServerSideError reports whether the error is ours or our upstream account's rather than
+// the caller's. Renderers must not make such failures look like a problem with the caller's
+// request, key or quota.
+func isServerSideError(errorCode ErrorCode) bool {
+	if errorCode == ErrorCodeUpstreamInsufficientQuota {
+		// our upstream account ran dry, not the caller's
+		return true
+	}
+	switch errorCode.Category() {
+	case CategorySystem, CategoryChannel, CategoryDatabase:
+		return true
+	}
+	return false
 }
 
-// ErrorCodeFromString converts a string representation to an ErrorCode
-// Returns ErrorCodeInvalidRequest if not found
-func ErrorCodeFromString(s string) ErrorCode {
-	for code, str := range errorCodeStrings {
-		if str == s {
-			return code
-		}
+// errorCodeFromUpstreamStatus infers an error code from an upstream HTTP status code
+// Used when the upstream error body carries no usable code or type
+func errorCodeFromUpstreamStatus(statusCode int) (ErrorCode, bool) {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return ErrorCodeInvalidRequest, true
+	case http.StatusUnauthorized:
+		return ErrorCodeChannelInvalidKey, true
+	case http.StatusForbidden:
+		return ErrorCodeChannelPermissionDenied, true
+	case http.StatusNotFound:
+		return ErrorCodeModelNotFound, true
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return ErrorCodeChannelResponseTimeExceeded, true
+	case http.StatusPaymentRequired:
+		return ErrorCodeUpstreamInsufficientQuota, true
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodeRequestTooLarge, true
+	case http.StatusTooManyRequests:
+		return ErrorCodeRateLimitExceeded, true
+	case StatusUpstreamOverloaded:
+		return ErrorCodeUpstreamOverloaded, true
+	case http.StatusServiceUnavailable:
+		return ErrorCodeServiceUnavailable, true
+	case http.StatusInternalServerError, http.StatusBadGateway:
+		return ErrorCodeBadResponse, true
+	}
+	if statusCode >= 400 && statusCode < 500 {
+		return ErrorCodeInvalidRequest, true
+	}
+	if statusCode >= 500 {
+		return ErrorCodeBadResponseStatusCode, true
 	}
-	return ErrorCodeInvalidRequest
+	return 0, false
 }
 
 // IsValid checks if the error code is valid
 func (c ErrorCode) IsValid() bool {
-	_, ok := errorCodeStrings[c]
+	_, ok := errorRegistry[c]
 	return ok
 }
 
@@ -0,0 +1,144 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/QuantumNous/new-api/common"
+)
+
+// GeminiError is the inner object of Google's error envelope:
+// {"error": {"code": 429, "message": "...", "status": "RESOURCE_EXHAUSTED", "details": [...]}}
+type GeminiError struct {
+	Code    int               `json:"code"`
+	Message string            `json:"message"`
+	Status  string            `json:"status"`
+	Details []json.RawMessage `json:"details,omitempty"`
+}
+
+// Google RPC canonical status names used in the Gemini error envelope
+const (
+	GeminiStatusInvalidArgument    = "INVALID_ARGUMENT"
+	GeminiStatusFailedPrecondition = "FAILED_PRECONDITION"
+	GeminiStatusOutOfRange         = "OUT_OF_RANGE"
+	GeminiStatusUnauthenticated    = "UNAUTHENTICATED"
+	GeminiStatusPermissionDenied   = "PERMISSION_DENIED"
+	GeminiStatusNotFound           = "NOT_FOUND"
+	GeminiStatusAborted            = "ABORTED"
+	GeminiStatusAlreadyExists      = "ALREADY_EXISTS"
+	GeminiStatusResourceExhausted  = "RESOURCE_EXHAUSTED"
+	GeminiStatusCancelled          = "CANCELLED"
+	GeminiStatusDataLoss           = "DATA_LOSS"
+	GeminiStatusUnknown            = "UNKNOWN"
+	GeminiStatusInternal           = "INTERNAL"
+	GeminiStatusUnimplemented      = "UNIMPLEMENTED"
+	GeminiStatusUnavailable        = "UNAVAILABLE"
+	GeminiStatusDeadlineExceeded   = "DEADLINE_EXCEEDED"
+)
+
+// geminiStatusErrorCodes maps Gemini status names to error codes
+var geminiStatusErrorCodes = map[string]ErrorCode{
+	GeminiStatusInvalidArgument:    ErrorCodeInvalidRequest,
+	GeminiStatusFailedPrecondition: ErrorCodeInvalidRequest,
+	GeminiStatusOutOfRange:         ErrorCodeInvalidRequest,
+	GeminiStatusAlreadyExists:      ErrorCodeInvalidRequest,
+	GeminiStatusAborted:            ErrorCodeInvalidRequest,
+	GeminiStatusUnauthenticated:    ErrorCodeChannelInvalidKey,
+	GeminiStatusPermissionDenied:   ErrorCodeChannelPermissionDenied,
+	GeminiStatusNotFound:           ErrorCodeModelNotFound,
+	GeminiStatusResourceExhausted:  ErrorCodeRateLimitExceeded,
+	GeminiStatusCancelled:          ErrorCodeBadResponse,
+	GeminiStatusDataLoss:           ErrorCodeBadResponse,
+	GeminiStatusUnknown:            ErrorCodeBadResponse,
+	GeminiStatusInternal:           ErrorCodeBadResponse,
+	GeminiStatusUnimplemented:      ErrorCodeBadResponse,
+	GeminiStatusUnavailable:        ErrorCodeServiceUnavailable,
+	GeminiStatusDeadlineExceeded:   ErrorCodeChannelResponseTimeExceeded,
+}
+
+// geminiStatusFromHTTPStatus returns the canonical Gemini status name for an HTTP status code
+func geminiStatusFromHTTPStatus(statusCode int) string {
+	switch statusCode {
+	case http.StatusBadRequest:
+		return GeminiStatusInvalidArgument
+	case http.StatusUnauthorized:
+		return GeminiStatusUnauthenticated
+	case http.StatusForbidden:
+		return GeminiStatusPermissionDenied
+	case http.StatusNotFound:
+		return GeminiStatusNotFound
+	case http.StatusConflict:
+		return GeminiStatusAborted
+	case http.StatusPaymentRequired, http.StatusTooManyRequests:
+		return GeminiStatusResourceExhausted
+	case StatusClientClosedRequest:
+		return GeminiStatusCancelled
+	case http.StatusNotImplemented:
+		return GeminiStatusUnimplemented
+	case http.StatusServiceUnavailable:
+		return GeminiStatusUnavailable
+	case http.StatusGatewayTimeout:
+		return GeminiStatusDeadlineExceeded
+	}
+	if statusCode >= 400 && statusCode < 500 {
+		return GeminiStatusFailedPrecondition
+	}
+	return GeminiStatusInternal
+}
+
+func WithGeminiError(geminiError GeminiError, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
+	if statusCode == 0 {
+		statusCode = geminiError.Code
+	}
+	if geminiError.Code == 0 {
+		geminiError.Code = statusCode
+	}
+	upstreamCode := geminiError.Status
+	errorCode, ok := geminiStatusErrorCodes[geminiError.Status]
+	if !ok {
+		errorCode, ok = errorCodeFromUpstreamStatus(geminiError.Code)
+		if !ok {
+			errorCode = ErrorCodeBadResponse
+		}
+	}
+	if geminiError.Status == "" {
+		geminiError.Status = geminiStatusFromHTTPStatus(geminiError.Code)
+	}
+	e := &NewAPIError{
+		RelayError:   geminiError,
+		errorType:    ErrorTypeGeminiError,
+		StatusCode:   statusCode,
+		Err:          errors.New(geminiError.Message),
+		errorCode:    errorCode,
+		upstreamCode: upstreamCode,
+		Level:        errorCode.DefaultLevel(), // Set default level
+	}
+	for _, op := range ops {
+		op(e)
+	}
+	return e
+}
+
+func (e *NewAPIError) ToGeminiError() GeminiError {
+	var result GeminiError
+	switch e.errorType {
+	case ErrorTypeGeminiError:
+		if geminiError, ok := e.RelayError.(GeminiError); ok {
+			result = geminiError
+		}
+	default:
+		result = GeminiError{
+			Code:    e.StatusCode,
+			Message: e.Error(),
+			Status:  geminiStatusFromHTTPStatus(e.StatusCode),
+		}
+	}
+	if e.errorCode != ErrorCodeCountTokenFailed {
+		result.Message = common.MaskSensitiveInfo(result.Message)
+	}
+	if result.Message == "" {
+		result.Message = string(e.errorType)
+	}
+	return result
+}
@@ -0,0 +1,82 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestWithGeminiError verifies Gemini status names map to error codes
+func TestWithGeminiError(t *testing.T) {
+	tests := []struct {
+		name       string
+		geminiErr  GeminiError
+		statusCode int
+		expected   ErrorCode
+	}{
+		{"ResourceExhausted", GeminiError{Code: 429, Status: GeminiStatusResourceExhausted}, 429, ErrorCodeRateLimitExceeded},
+		{"PermissionDenied", GeminiError{Code: 403, Status: GeminiStatusPermissionDenied}, 403, ErrorCodeChannelPermissionDenied},
+		{"Unavailable", GeminiError{Code: 503, Status: GeminiStatusUnavailable}, 503, ErrorCodeServiceUnavailable},
+		{"InvalidArgument", GeminiError{Code: 400, Status: GeminiStatusInvalidArgument}, 400, ErrorCodeInvalidRequest},
+		{"MissingStatus", GeminiError{Code: 404}, 404, ErrorCodeModelNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := WithGeminiError(tt.geminiErr, tt.statusCode)
+			if e.GetErrorCode() != tt.expected {
+				t.Errorf("WithGeminiError() errorCode = %d, want %d", e.GetErrorCode(), tt.expected)
+			}
+			if e.GetErrorType() != ErrorTypeGeminiError {
+				t.Errorf("WithGeminiError() errorType = %v, want %v", e.GetErrorType(), ErrorTypeGeminiError)
+			}
+			if e.StatusCode != tt.statusCode {
+				t.Errorf("WithGeminiError() StatusCode = %v, want %v", e.StatusCode, tt.statusCode)
+			}
+		})
+	}
+}
+
+// TestToGeminiError verifies any error renders into the Gemini envelope
+func TestToGeminiError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            *NewAPIError
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "Gemini passthrough",
+			err:            WithGeminiError(GeminiError{Code: 429, Message: "quota", Status: GeminiStatusResourceExhausted}, 429),
+			expectedCode:   http.StatusTooManyRequests,
+			expectedStatus: GeminiStatusResourceExhausted,
+		},
+		{
+			name:           "Claude error",
+			err:            WithClaudeError(ClaudeError{Type: "overloaded_error", Message: "overloaded"}, http.StatusServiceUnavailable),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: GeminiStatusUnavailable,
+		},
+		{
+			name:           "Internal error",
+			err:            NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey),
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: GeminiStatusUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.err.ToGeminiError()
+			if result.Code != tt.expectedCode {
+				t.Errorf("ToGeminiError() Code = %v, want %v", result.Code, tt.expectedCode)
+			}
+			if result.Status != tt.expectedStatus {
+				t.Errorf("ToGeminiError() Status = %v, want %v", result.Status, tt.expectedStatus)
+			}
+			if result.Message == "" {
+				t.Error("ToGeminiError() Message is empty")
+			}
+		})
+	}
+}
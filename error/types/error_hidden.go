@@ -0,0 +1,74 @@
+package types
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// DetectHiddenError inspects the body of a successful upstream response for an error
+// envelope in any supported format. Several providers answer HTTP 200 with an error body,
+// which must not be billed or counted as a healthy response. It returns nil when the body
+// is not an error; the status of a detected error is corrected to an error status.
+func DetectHiddenError(statusCode int, body []byte, ops ...NewAPIErrorOptions) *NewAPIError {
+	trimmed := bytes.TrimSpace(body)
+	if !mayContainErrorEnvelope(trimmed) {
+		return nil
+	}
+	return detectErrorEnvelope(statusCode, trimmed, ops...)
+}
+
+// DetectStreamError inspects one SSE event for an error envelope in any supported format,
+// such as an OpenAI {"error": {...}} chunk or a Claude "event: error". The event may be given
+// as the raw "data: ..." line(s) or as the bare payload. It returns nil when the event is not an error.
+func DetectStreamError(event []byte, ops ...NewAPIErrorOptions) *NewAPIError {
+	data := sseEventData(event)
+	if !mayContainErrorEnvelope(data) {
+		return nil
+	}
+	// the stream was already answered with 200, so the status comes from the error itself
+	return detectErrorEnvelope(0, data, ops...)
+}
+
+// detectErrorEnvelope parses an error envelope found in a response that claimed success
+func detectErrorEnvelope(statusCode int, body []byte, ops ...NewAPIErrorOptions) *NewAPIError {
+	if statusCode < http.StatusBadRequest {
+		// an upstream status of 2xx says nothing about the failure, let the body decide
+		statusCode = 0
+	}
+	e := parseUpstreamErrorJSON(statusCode, body, true, ops...)
+	if e == nil {
+		return nil
+	}
+	if e.StatusCode < http.StatusBadRequest {
+		e.StatusCode = e.errorCode.HTTPStatusCode()
+	}
+	if geminiError, ok := e.RelayError.(GeminiError); ok && geminiError.Code < http.StatusBadRequest {
+		geminiError.Code = e.StatusCode
+		e.RelayError = geminiError
+	}
+	return e
+}
+
+// mayContainErrorEnvelope is a cheap pre-check so that ordinary stream chunks skip JSON decoding
+func mayContainErrorEnvelope(data []byte) bool {
+	if len(data) == 0 || (data[0] != '{' && data[0] != '[') {
+		return false
+	}
+	return bytes.Contains(data, []byte(`"error"`)) || bytes.Contains(data, []byte(`"description"`))
+}
+
+// sseEventData returns the payload of an SSE event, joining multiple "data:" lines
+func sseEventData(event []byte) []byte {
+	event = bytes.TrimSpace(event)
+	if !bytes.HasPrefix(event, []byte("data:")) && !bytes.HasPrefix(event, []byte("event:")) {
+		return event
+	}
+	var data [][]byte
+	for _, line := range bytes.Split(event, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r")
+		if value, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(value, []byte(" ")))
+		}
+	}
+	return bytes.TrimSpace(bytes.Join(data, []byte("\n")))
+}
@@ -0,0 +1,106 @@
+package types
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestDetectHiddenError verifies error envelopes in 200 bodies are detected and success bodies are not
+func TestDetectHiddenError(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		expectedType   ErrorType
+		expectedCode   ErrorCode
+		expectedStatus int
+	}{
+		{"OpenAI envelope", `{"error":{"message":"Insufficient balance","type":"insufficient_quota","code":"insufficient_quota"}}`, ErrorTypeOpenAIError, ErrorCodeUpstreamInsufficientQuota, http.StatusServiceUnavailable},
+		{"OpenAI numeric code", `{"error":{"message":"rate limited","code":429}}`, ErrorTypeOpenAIError, ErrorCodeRateLimitExceeded, http.StatusTooManyRequests},
+		{"String error", `{"error":"model not found","type":"not_found_error"}`, ErrorTypeOpenAIError, ErrorCodeModelNotFound, http.StatusNotFound},
+		{"Claude envelope", `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, ErrorTypeClaudeError, ErrorCodeUpstreamOverloaded, http.StatusServiceUnavailable},
+		{"Gemini envelope", `{"error":{"code":429,"message":"quota","status":"RESOURCE_EXHAUSTED"}}`, ErrorTypeGeminiError, ErrorCodeRateLimitExceeded, http.StatusTooManyRequests},
+		{"Gemini array", `[{"error":{"code":503,"message":"overloaded","status":"UNAVAILABLE"}}]`, ErrorTypeGeminiError, ErrorCodeServiceUnavailable, http.StatusServiceUnavailable},
+		{"Midjourney failure", `{"code":24,"description":"banned prompt","result":""}`, ErrorTypeMidjourneyError, ErrorCodePromptBlocked, http.StatusBadRequest},
+		{"Responses error event", `{"type":"error","code":"context_length_exceeded","message":"too long","param":"input","sequence_number":0}`, ErrorTypeOpenAIError, ErrorCodeContextLengthExceeded, http.StatusBadRequest},
+		{"Responses failed event", `{"type":"response.failed","sequence_number":5,"response":{"id":"resp_1","status":"failed","error":{"code":"server_error","message":"The model failed"}}}`, ErrorTypeOpenAIError, ErrorCodeBadResponse, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := DetectHiddenError(http.StatusOK, []byte(tt.body))
+			if e == nil {
+				t.Fatalf("DetectHiddenError(%s) = nil, want error", tt.body)
+			}
+			if e.GetErrorType() != tt.expectedType {
+				t.Errorf("DetectHiddenError() errorType = %q, want %q", e.GetErrorType(), tt.expectedType)
+			}
+			if e.GetErrorCode() != tt.expectedCode {
+				t.Errorf("DetectHiddenError() errorCode = %d, want %d", e.GetErrorCode(), tt.expectedCode)
+			}
+			if e.StatusCode != tt.expectedStatus {
+				t.Errorf("DetectHiddenError() StatusCode = %d, want %d", e.StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+// TestDetectHiddenErrorSuccess verifies ordinary success bodies are not reported as errors
+func TestDetectHiddenErrorSuccess(t *testing.T) {
+	bodies := []string{
+		``,
+		`{"id":"chatcmpl-1","object":"chat.completion","choices":[{"message":{"role":"assistant","content":"the error is on line 3"}}]}`,
+		`{"id":"batch_1","object":"batch","error":null}`,
+		`{"error":{}}`,
+		`{"message":"ok","detail":"done"}`,
+		`{"code":1,"description":"submitted","result":"1712345678"}`,
+		`{"code":21,"description":"task existed","result":"1712345678"}`,
+		`{"code":22,"description":"排队中","result":"1234"}`,
+		`{"type":"message","content":[{"type":"text","text":"{\"error\":\"quoted\"}"}]}`,
+		`not json at all`,
+	}
+	for _, body := range bodies {
+		if e := DetectHiddenError(http.StatusOK, []byte(body)); e != nil {
+			t.Errorf("DetectHiddenError(%s) = %v, want nil", body, e)
+		}
+	}
+}
+
+// TestDetectStreamError verifies error events in SSE streams are detected
+func TestDetectStreamError(t *testing.T) {
+	tests := []struct {
+		name         string
+		event        string
+		expectedCode ErrorCode
+	}{
+		{"No error", `data: {"id":"chatcmpl-1","choices":[{"delta":{"content":"hi"}}]}`, 0},
+		{"Done", `data: [DONE]`, 0},
+		{"OpenAI chunk", `data: {"error":{"message":"server overloaded","type":"server_error"}}`, ErrorCodeBadResponse},
+		{"Claude event", "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}", ErrorCodeUpstreamOverloaded},
+		{"Gemini chunk", `data: {"error":{"code":500,"message":"internal","status":"INTERNAL"}}`, ErrorCodeBadResponse},
+		{"Bare payload", `{"error":{"message":"context too long","code":"context_length_exceeded"}}`, ErrorCodeContextLengthExceeded},
+		{"Responses error event", "event: error\ndata: {\"type\":\"error\",\"code\":\"rate_limit_exceeded\",\"message\":\"slow down\",\"param\":null,\"sequence_number\":4}", ErrorCodeRateLimitExceeded},
+		{"Responses failed event", "event: response.failed\ndata: {\"type\":\"response.failed\",\"sequence_number\":9,\"response\":{\"id\":\"resp_1\",\"status\":\"failed\",\"error\":{\"code\":\"server_error\",\"message\":\"The model failed\"}}}", ErrorCodeBadResponse},
+		{"Responses completed event", `data: {"type":"response.completed","response":{"id":"resp_1","status":"completed","error":null}}`, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := DetectStreamError([]byte(tt.event))
+			if tt.expectedCode == 0 {
+				if e != nil {
+					t.Errorf("DetectStreamError() = %v, want nil", e)
+				}
+				return
+			}
+			if e == nil {
+				t.Fatalf("DetectStreamError() = nil, want error code %d", tt.expectedCode)
+			}
+			if e.GetErrorCode() != tt.expectedCode {
+				t.Errorf("DetectStreamError() errorCode = %d, want %d", e.GetErrorCode(), tt.expectedCode)
+			}
+			if e.StatusCode < http.StatusBadRequest {
+				t.Errorf("DetectStreamError() StatusCode = %d, want an error status", e.StatusCode)
+			}
+		})
+	}
+}
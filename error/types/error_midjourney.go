@@ -0,0 +1,115 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/QuantumNous/new-api/common"
+)
+
+// MidjourneyError is the midjourney-proxy response body: {"code": 23, "description": "...", "result": ""}
+type MidjourneyError struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	Result      string `json:"result"`
+}
+
+// midjourney-proxy response codes (ReturnCode in midjourney-proxy)
+const (
+	MidjourneyCodeSuccess         = 1
+	MidjourneyCodeNotFound        = 3
+	MidjourneyCodeValidationError = 4
+	MidjourneyCodeFailure         = 9
+	MidjourneyCodeExisted         = 21
+	MidjourneyCodeInQueue         = 22
+	MidjourneyCodeQueueRejected   = 23
+	MidjourneyCodeBannedPrompt    = 24
+)
+
+// midjourneyCodeErrorCodes maps midjourney-proxy codes to error codes.
+// MidjourneyCodeInQueue is an accepted submission and never an error.
+var midjourneyCodeErrorCodes = map[int]ErrorCode{
+	MidjourneyCodeNotFound:        ErrorCodeTaskNotFound,
+	MidjourneyCodeValidationError: ErrorCodeInvalidRequest,
+	MidjourneyCodeFailure:         ErrorCodeBadResponse,
+	MidjourneyCodeExisted:         ErrorCodeTaskAlreadyExists,
+	MidjourneyCodeQueueRejected:   ErrorCodeRateLimitExceeded,
+	MidjourneyCodeBannedPrompt:    ErrorCodePromptBlocked,
+}
+
+// midjourneyAccepted reports whether a midjourney-proxy code accepts the submission. An
+// existing or queued task still returns a task id in result, so neither is a failure.
+func midjourneyAccepted(code int) bool {
+	switch code {
+	case MidjourneyCodeSuccess, MidjourneyCodeExisted, MidjourneyCodeInQueue:
+		return true
+	}
+	return false
+}
+
+// midjourneyCodeFromErrorCode returns the midjourney-proxy code for an error code
+func midjourneyCodeFromErrorCode(errorCode ErrorCode) int {
+	switch errorCode {
+	case ErrorCodeTaskNotFound:
+		return MidjourneyCodeNotFound
+	case ErrorCodeInvalidRequest, ErrorCodeBadRequestBody:
+		return MidjourneyCodeValidationError
+	case ErrorCodeTaskAlreadyExists:
+		return MidjourneyCodeExisted
+	case ErrorCodeRateLimitExceeded:
+		return MidjourneyCodeQueueRejected
+	case ErrorCodePromptBlocked, ErrorCodeSensitiveWordsDetected:
+		return MidjourneyCodeBannedPrompt
+	default:
+		return MidjourneyCodeFailure
+	}
+}
+
+// WithMidjourneyError builds a NewAPIError from a midjourney-proxy response.
+// midjourney-proxy reports failures with HTTP 200, so a 2xx (or zero) status code
+// is replaced with the status derived from the mapped error code.
+func WithMidjourneyError(midjourneyError MidjourneyError, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
+	errorCode, ok := midjourneyCodeErrorCodes[midjourneyError.Code]
+	if !ok {
+		errorCode = ErrorCodeBadResponse
+	}
+	if statusCode < http.StatusBadRequest {
+		statusCode = errorCode.HTTPStatusCode()
+	}
+	e := &NewAPIError{
+		RelayError:   midjourneyError,
+		errorType:    ErrorTypeMidjourneyError,
+		StatusCode:   statusCode,
+		Err:          errors.New(midjourneyError.Description),
+		errorCode:    errorCode,
+		upstreamCode: strconv.Itoa(midjourneyError.Code),
+		Level:        errorCode.DefaultLevel(), // Set default level
+	}
+	for _, op := range ops {
+		op(e)
+	}
+	return e
+}
+
+func (e *NewAPIError) ToMidjourneyError() MidjourneyError {
+	var result MidjourneyError
+	switch e.errorType {
+	case ErrorTypeMidjourneyError:
+		if midjourneyError, ok := e.RelayError.(MidjourneyError); ok {
+			result = midjourneyError
+		}
+	default:
+		result = MidjourneyError{
+			Code:        midjourneyCodeFromErrorCode(e.errorCode),
+			Description: e.Error(),
+		}
+	}
+	if e.errorCode != ErrorCodeCountTokenFailed {
+		result.Description = common.MaskSensitiveInfo(result.Description)
+	}
+	if result.Description == "" {
+		result.Description = string(e.errorType)
+	}
+	return result
+}
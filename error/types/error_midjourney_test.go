@@ -0,0 +1,53 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestWithMidjourneyError verifies midjourney-proxy codes map to error codes
+func TestWithMidjourneyError(t *testing.T) {
+	tests := []struct {
+		name           string
+		code           int
+		expected       ErrorCode
+		expectedStatus int
+	}{
+		{"NotFound", MidjourneyCodeNotFound, ErrorCodeTaskNotFound, http.StatusNotFound},
+		{"ValidationError", MidjourneyCodeValidationError, ErrorCodeInvalidRequest, http.StatusBadRequest},
+		{"Failure", MidjourneyCodeFailure, ErrorCodeBadResponse, ErrorCodeBadResponse.HTTPStatusCode()},
+		{"Existed", MidjourneyCodeExisted, ErrorCodeTaskAlreadyExists, http.StatusConflict},
+		{"QueueRejected", MidjourneyCodeQueueRejected, ErrorCodeRateLimitExceeded, http.StatusTooManyRequests},
+		{"BannedPrompt", MidjourneyCodeBannedPrompt, ErrorCodePromptBlocked, http.StatusBadRequest},
+		{"Unknown", 99, ErrorCodeBadResponse, http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := WithMidjourneyError(MidjourneyError{Code: tt.code, Description: "failed"}, http.StatusOK)
+			if e.GetErrorCode() != tt.expected {
+				t.Errorf("WithMidjourneyError() errorCode = %d, want %d", e.GetErrorCode(), tt.expected)
+			}
+			if e.StatusCode != tt.expectedStatus {
+				t.Errorf("WithMidjourneyError() StatusCode = %v, want %v", e.StatusCode, tt.expectedStatus)
+			}
+		})
+	}
+}
+
+// TestToMidjourneyError verifies any error renders into the midjourney-proxy format
+func TestToMidjourneyError(t *testing.T) {
+	upstream := WithMidjourneyError(MidjourneyError{Code: MidjourneyCodeBannedPrompt, Description: "banned prompt"}, http.StatusOK)
+	if result := upstream.ToMidjourneyError(); result.Code != MidjourneyCodeBannedPrompt || result.Description != "banned prompt" {
+		t.Errorf("ToMidjourneyError() = %+v, want passthrough", result)
+	}
+	if result := upstream.ToOpenAIError(); result.Code.String() != "prompt_blocked" {
+		t.Errorf("ToOpenAIError() Code = %v, want prompt_blocked", result.Code)
+	}
+
+	internal := NewError(errors.New("rate limited"), ErrorCodeRateLimitExceeded)
+	if result := internal.ToMidjourneyError(); result.Code != MidjourneyCodeQueueRejected {
+		t.Errorf("ToMidjourneyError() Code = %v, want %v", result.Code, MidjourneyCodeQueueRejected)
+	}
+}
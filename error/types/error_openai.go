@@ -0,0 +1,108 @@
+package types
+
+import "net/http"
+
+// OpenAI API error types used for outbound errors
+const (
+	OpenAIErrorTypeInvalidRequest    = "invalid_request_error"
+	OpenAIErrorTypeAuthentication    = "authentication_error"
+	OpenAIErrorTypePermission        = "permission_error"
+	OpenAIErrorTypeNotFound          = "not_found_error"
+	OpenAIErrorTypeRateLimit         = "rate_limit_error"
+	OpenAIErrorTypeInsufficientQuota = "insufficient_quota"
+	OpenAIErrorTypeServer            = "server_error"
+)
+
+// openAICodeErrorCodes maps codes reported by OpenAI and OpenAI-compatible providers to error codes
+var openAICodeErrorCodes = map[string]ErrorCode{
+	"context_length_exceeded":    ErrorCodeContextLengthExceeded,
+	"string_above_max_length":    ErrorCodeContextLengthExceeded,
+	"insufficient_quota":         ErrorCodeUpstreamInsufficientQuota,
+	"billing_hard_limit_reached": ErrorCodeUpstreamInsufficientQuota,
+	"billing_not_active":         ErrorCodeUpstreamInsufficientQuota,
+	"invalid_api_key":            ErrorCodeChannelInvalidKey,
+	"account_deactivated":        ErrorCodeChannelInvalidKey,
+	"model_not_found":            ErrorCodeModelNotFound,
+	"rate_limit_exceeded":        ErrorCodeRateLimitExceeded,
+	"server_error":               ErrorCodeBadResponse,
+	"content_filter":             ErrorCodePromptBlocked,
+	"content_policy_violation":   ErrorCodePromptBlocked,
+	// another new-api gateway reporting our own account at it out of quota
+	"insufficient_user_quota":        ErrorCodeUpstreamInsufficientQuota,
+	"quota_exceeded":                 ErrorCodeUpstreamInsufficientQuota,
+	"pre_consume_token_quota_failed": ErrorCodeUpstreamInsufficientQuota,
+}
+
+// openAITypeErrorCodes maps error types reported by OpenAI and OpenAI-compatible providers to error codes
+var openAITypeErrorCodes = map[string]ErrorCode{
+	"authentication_error": ErrorCodeChannelInvalidKey,
+	"permission_error":     ErrorCodeChannelPermissionDenied,
+	"not_found_error":      ErrorCodeModelNotFound,
+	"rate_limit_error":     ErrorCodeRateLimitExceeded,
+	"tokens":               ErrorCodeRateLimitExceeded,
+	"requests":             ErrorCodeRateLimitExceeded,
+	"insufficient_quota":   ErrorCodeUpstreamInsufficientQuota,
+	"server_error":         ErrorCodeBadResponse,
+	"api_error":            ErrorCodeBadResponse,
+	"overloaded_error":     ErrorCodeUpstreamOverloaded,
+}
+
+// openAIUpstreamErrorCode combines the code, type and HTTP status of an upstream
+// OpenAI-style error into an error code. The code is the most specific signal,
+// followed by the type; the generic invalid_request_error type defers to the status.
+func openAIUpstreamErrorCode(code string, errorType string, statusCode int) ErrorCode {
+	if statusCode == StatusUpstreamOverloaded {
+		return ErrorCodeUpstreamOverloaded
+	}
+	if errorCode, ok := openAICodeErrorCodes[code]; ok {
+		return errorCode
+	}
+	if errorCode, ok := errorCodeFromOwnName(code); ok {
+		return errorCode
+	}
+	if errorCode, ok := openAITypeErrorCodes[errorType]; ok {
+		return errorCode
+	}
+	if errorType == OpenAIErrorTypeInvalidRequest {
+		if errorCode, ok := errorCodeFromUpstreamStatus(statusCode); ok && statusCode < http.StatusInternalServerError {
+			return errorCode
+		}
+		return ErrorCodeInvalidRequest
+	}
+	if errorCode, ok := errorCodeFromUpstreamStatus(statusCode); ok {
+		return errorCode
+	}
+	return ErrorCodeUnknownUpstream
+}
+
+// openAIErrorTypeFor derives the outbound OpenAI error type from an error code and HTTP status
+func openAIErrorTypeFor(errorCode ErrorCode, statusCode int) string {
+	switch errorCode {
+	case ErrorCodeRateLimitExceeded:
+		return OpenAIErrorTypeRateLimit
+	case ErrorCodeModelNotFound:
+		return OpenAIErrorTypeNotFound
+	}
+	if isServerSideError(errorCode) {
+		return OpenAIErrorTypeServer
+	}
+	if errorCode.Category() == CategoryQuota && statusCode < http.StatusInternalServerError {
+		return OpenAIErrorTypeInsufficientQuota
+	}
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		return OpenAIErrorTypeAuthentication
+	case statusCode == http.StatusPaymentRequired:
+		return OpenAIErrorTypeInsufficientQuota
+	case statusCode == http.StatusForbidden:
+		return OpenAIErrorTypePermission
+	case statusCode == http.StatusNotFound:
+		return OpenAIErrorTypeNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return OpenAIErrorTypeRateLimit
+	case statusCode >= http.StatusInternalServerError:
+		return OpenAIErrorTypeServer
+	default:
+		return OpenAIErrorTypeInvalidRequest
+	}
+}
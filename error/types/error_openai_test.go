@@ -0,0 +1,113 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestWithOpenAIErrorTaxonomy verifies upstream code, type and status combine into the right error code
+func TestWithOpenAIErrorTaxonomy(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       any
+		errorType  string
+		statusCode int
+		expected   ErrorCode
+	}{
+		{"context_length_exceeded", "context_length_exceeded", "invalid_request_error", http.StatusBadRequest, ErrorCodeContextLengthExceeded},
+		{"insufficient_quota", "insufficient_quota", "insufficient_quota", http.StatusTooManyRequests, ErrorCodeUpstreamInsufficientQuota},
+		{"invalid_api_key", "invalid_api_key", "invalid_request_error", http.StatusUnauthorized, ErrorCodeChannelInvalidKey},
+		{"model_not_found", "model_not_found", "invalid_request_error", http.StatusNotFound, ErrorCodeModelNotFound},
+		{"rate_limit_exceeded", "rate_limit_exceeded", "requests", http.StatusTooManyRequests, ErrorCodeRateLimitExceeded},
+		{"server_error", "server_error", "server_error", http.StatusInternalServerError, ErrorCodeBadResponse},
+		{"tokens type", nil, "tokens", http.StatusTooManyRequests, ErrorCodeRateLimitExceeded},
+		{"invalid_request_error 400", nil, "invalid_request_error", http.StatusBadRequest, ErrorCodeInvalidRequest},
+		{"invalid_request_error 401", nil, "invalid_request_error", http.StatusUnauthorized, ErrorCodeChannelInvalidKey},
+		{"invalid_request_error 500", nil, "invalid_request_error", http.StatusInternalServerError, ErrorCodeInvalidRequest},
+		{"numeric code", 429, "", http.StatusTooManyRequests, ErrorCodeRateLimitExceeded},
+		{"null code 503", nil, "", http.StatusServiceUnavailable, ErrorCodeServiceUnavailable},
+		{"upstream gateway out of quota", "insufficient_user_quota", "new_api_error", http.StatusForbidden, ErrorCodeUpstreamInsufficientQuota},
+		{"unknown everything", "weird_code", "weird_type", 0, ErrorCodeUnknownUpstream},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := WithOpenAIError(OpenAIError{Message: "upstream failure", Type: tt.errorType, Code: NewUpstreamCode(tt.code)}, tt.statusCode)
+			if e.GetErrorCode() != tt.expected {
+				t.Errorf("WithOpenAIError(%v, %q, %d) errorCode = %d, want %d", tt.code, tt.errorType, tt.statusCode, e.GetErrorCode(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewOpenAIErrorKeepsInternalCode verifies internally built OpenAI errors keep their code
+func TestNewOpenAIErrorKeepsInternalCode(t *testing.T) {
+	e := InitOpenAIError(ErrorCodeChannelNoAvailableKey, http.StatusServiceUnavailable)
+	if e.GetErrorCode() != ErrorCodeChannelNoAvailableKey {
+		t.Errorf("InitOpenAIError() errorCode = %d, want %d", e.GetErrorCode(), ErrorCodeChannelNoAvailableKey)
+	}
+	if e.GetUpstreamCode() != "" {
+		t.Errorf("InitOpenAIError() upstreamCode = %q, want empty", e.GetUpstreamCode())
+	}
+}
+
+// TestToOpenAIError verifies outbound OpenAI errors use OpenAI types and stable string codes
+func TestToOpenAIError(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          *NewAPIError
+		expectedType string
+		expectedCode string
+	}{
+		{"Upstream rate limit", WithOpenAIError(OpenAIError{Message: "slow down", Type: "requests", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests), OpenAIErrorTypeRateLimit, "rate_limit_exceeded"},
+		{"Upstream out of quota", WithOpenAIError(OpenAIError{Message: "billing", Type: "insufficient_quota", Code: NewUpstreamCode("insufficient_quota")}, http.StatusTooManyRequests), OpenAIErrorTypeServer, "upstream_insufficient_quota"},
+		{"Upstream context length", WithOpenAIError(OpenAIError{Message: "too long", Type: "invalid_request_error", Code: NewUpstreamCode("context_length_exceeded"), Param: "messages"}, http.StatusBadRequest), OpenAIErrorTypeInvalidRequest, "context_length_exceeded"},
+		{"Claude authentication", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeAuthentication, Message: "bad key"}, http.StatusUnauthorized), OpenAIErrorTypeServer, "channel_invalid_key"},
+		{"Client unauthorized", NewError(errors.New("bad token"), ErrorCodeUnauthorized), OpenAIErrorTypeAuthentication, "unauthorized"},
+		{"Token expired", NewError(errors.New("token expired"), ErrorCodeTokenExpired), OpenAIErrorTypeAuthentication, "token_expired"},
+		{"User banned", NewError(errors.New("banned"), ErrorCodeUserBanned), OpenAIErrorTypePermission, "user_banned"},
+		{"User quota", NewError(errors.New("no quota"), ErrorCodeInsufficientUserQuota), OpenAIErrorTypeInsufficientQuota, "insufficient_user_quota"},
+		{"Bad request body", NewError(errors.New("bad json"), ErrorCodeBadRequestBody), OpenAIErrorTypeInvalidRequest, "bad_request_body"},
+		{"Database failure", NewError(errors.New("db down"), ErrorCodeQueryDataError), OpenAIErrorTypeServer, "query_data_error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.err.ToOpenAIError()
+			if result.Type != tt.expectedType {
+				t.Errorf("ToOpenAIError() Type = %q, want %q", result.Type, tt.expectedType)
+			}
+			if result.Code.String() != tt.expectedCode {
+				t.Errorf("ToOpenAIError() Code = %v, want %q", result.Code, tt.expectedCode)
+			}
+			if result.NumericCode != tt.err.GetErrorCode() {
+				t.Errorf("ToOpenAIError() NumericCode = %d, want %d", result.NumericCode, tt.err.GetErrorCode())
+			}
+		})
+	}
+}
+
+// TestToOpenAIErrorParam verifies Param is filled from the upstream or an option
+func TestToOpenAIErrorParam(t *testing.T) {
+	upstream := WithOpenAIError(OpenAIError{Message: "too long", Code: NewUpstreamCode("context_length_exceeded"), Param: "messages"}, http.StatusBadRequest)
+	if result := upstream.ToOpenAIError(); result.Param != "messages" {
+		t.Errorf("ToOpenAIError() Param = %q, want %q", result.Param, "messages")
+	}
+
+	internal := NewError(errors.New("bad model"), ErrorCodeInvalidRequest, ErrOptionWithParam("model"))
+	if result := internal.ToOpenAIError(); result.Param != "model" {
+		t.Errorf("ToOpenAIError() Param = %q, want %q", result.Param, "model")
+	}
+}
+
+// TestLegacyOpenAIErrorCode verifies the compatibility switch keeps the numeric code
+func TestLegacyOpenAIErrorCode(t *testing.T) {
+	LegacyOpenAIErrorCode = true
+	defer func() { LegacyOpenAIErrorCode = false }()
+
+	result := NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey).ToOpenAIError()
+	if result.Code.Kind() != UpstreamCodeInt || result.Code.String() != "3001" {
+		t.Errorf("ToOpenAIError() Code = %v (%s), want %d", result.Code, result.Code.Kind(), ErrorCodeChannelNoAvailableKey)
+	}
+}
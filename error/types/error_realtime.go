@@ -0,0 +1,207 @@
+package types
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// WebSocket close codes (RFC 6455 and the IANA registry) used to end realtime sessions
+const (
+	WebSocketClosePolicyViolation = 1008
+	WebSocketCloseInternalError   = 1011
+	WebSocketCloseTryAgainLater   = 1013
+	WebSocketCloseBadGateway      = 1014
+)
+
+// maxWebSocketCloseReasonLength is the longest close reason a control frame can carry
+const maxWebSocketCloseReasonLength = 123
+
+// RealtimeError is the error object of a realtime error event. EventID is the id of the
+// client event that caused the error, empty if the error was not caused by one.
+type RealtimeError struct {
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+	Param   string `json:"param,omitempty"`
+	EventID string `json:"event_id,omitempty"`
+}
+
+// RealtimeErrorEvent is the server event OpenAI's /v1/realtime WebSocket sends on errors:
+// {"type": "error", "event_id": "event_...", "error": {...}}
+type RealtimeErrorEvent struct {
+	Type    string        `json:"type"`
+	EventID string        `json:"event_id"`
+	Error   RealtimeError `json:"error"`
+}
+
+// RealtimeClose tells how a realtime session must be closed after a fatal error
+type RealtimeClose struct {
+	Code   int
+	Reason string
+}
+
+// newRealtimeEventID returns a server event id in OpenAI's "event_..." form
+func newRealtimeEventID() string {
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "event_" + hex.EncodeToString(b[:])
+}
+
+// ToRealtimeErrorEvent converts the error to a realtime error event. clientEventID is the
+// event_id of the client event being answered, so SDKs can correlate the error with it.
+func (e *NewAPIError) ToRealtimeErrorEvent(clientEventID string, lang string) RealtimeErrorEvent {
+	openAIError := e.ToOpenAIError()
+	if message, replace := e.clientMessage(lang); replace {
+		openAIError.Message = message
+	}
+	return RealtimeErrorEvent{
+		Type:    "error",
+		EventID: newRealtimeEventID(),
+		Error: RealtimeError{
+			Type:    openAIError.Type,
+			Code:    openAIError.Code.String(),
+			Message: openAIError.Message,
+			Param:   openAIError.Param,
+			EventID: clientEventID,
+		},
+	}
+}
+
+// RealtimeClose reports whether the realtime session must be closed after the error, and
+// with which close code. Rejected credentials and quota end the session with a policy
+// violation, failures on our side with an internal error, and upstream failures with bad
+// gateway or try again later. Errors in a single client event leave the session open.
+func (e *NewAPIError) RealtimeClose() (RealtimeClose, bool) {
+	if e == nil {
+		return RealtimeClose{}, false
+	}
+	var code int
+	switch category := e.errorCode.Category(); {
+	case category == CategoryAuth || category == CategoryQuota,
+		e.StatusCode == http.StatusUnauthorized, e.StatusCode == http.StatusPaymentRequired,
+		e.StatusCode == http.StatusForbidden:
+		code = WebSocketClosePolicyViolation
+	case category == CategorySystem || category == CategoryChannel || category == CategoryDatabase:
+		code = WebSocketCloseInternalError
+	case e.StatusCode == http.StatusServiceUnavailable || e.StatusCode == StatusUpstreamOverloaded:
+		code = WebSocketCloseTryAgainLater
+	case e.StatusCode >= http.StatusInternalServerError:
+		code = WebSocketCloseBadGateway
+	default:
+		return RealtimeClose{}, false
+	}
+	reason := truncateMessage(e.errorCode.String(), maxWebSocketCloseReasonLength)
+	return RealtimeClose{Code: code, Reason: reason}, true
+}
+
+// ResponsesErrorEvent is the Responses API stream event for errors:
+// {"type": "error", "code": "...", "message": "...", "param": null, "sequence_number": 3}
+type ResponsesErrorEvent struct {
+	Type           string  `json:"type"`
+	Code           string  `json:"code"`
+	Message        string  `json:"message"`
+	Param          *string `json:"param"`
+	SequenceNumber int     `json:"sequence_number"`
+}
+
+// ResponsesError is the error object of a failed Responses API response
+type ResponsesError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ResponsesFailedResponse is the response object carried by a response.failed event
+type ResponsesFailedResponse struct {
+	ID     string         `json:"id"`
+	Object string         `json:"object"`
+	Status string         `json:"status"`
+	Error  ResponsesError `json:"error"`
+}
+
+// ResponsesFailedEvent is the Responses API stream event for a response that failed after
+// it was created: {"type": "response.failed", "sequence_number": 5, "response": {...}}
+type ResponsesFailedEvent struct {
+	Type           string                  `json:"type"`
+	SequenceNumber int                     `json:"sequence_number"`
+	Response       ResponsesFailedResponse `json:"response"`
+}
+
+// ToResponsesErrorEvent converts the error to a Responses API error event
+func (e *NewAPIError) ToResponsesErrorEvent(sequenceNumber int, lang string) ResponsesErrorEvent {
+	openAIError := e.ToOpenAIError()
+	if message, replace := e.clientMessage(lang); replace {
+		openAIError.Message = message
+	}
+	event := ResponsesErrorEvent{
+		Type:           "error",
+		Code:           openAIError.Code.String(),
+		Message:        openAIError.Message,
+		SequenceNumber: sequenceNumber,
+	}
+	if openAIError.Param != "" {
+		event.Param = &openAIError.Param
+	}
+	return event
+}
+
+// responsesErrorCode returns the code of a failed response. The Responses API reserves
+// server_error and rate_limit_exceeded for failures the client cannot fix by changing the input.
+func (e *NewAPIError) responsesErrorCode() string {
+	switch {
+	case e.errorCode == ErrorCodeRateLimitExceeded:
+		return "rate_limit_exceeded"
+	case e.StatusCode >= http.StatusInternalServerError:
+		return "server_error"
+	default:
+		return e.openAIErrorCode().String()
+	}
+}
+
+// ToResponsesFailedEvent converts the error to a response.failed event for the response
+// with the given id
+func (e *NewAPIError) ToResponsesFailedEvent(responseID string, sequenceNumber int, lang string) ResponsesFailedEvent {
+	message := e.ToOpenAIError().Message
+	if localized, replace := e.clientMessage(lang); replace {
+		message = localized
+	}
+	return ResponsesFailedEvent{
+		Type:           "response.failed",
+		SequenceNumber: sequenceNumber,
+		Response: ResponsesFailedResponse{
+			ID:     responseID,
+			Object: "response",
+			Status: "failed",
+			Error: ResponsesError{
+				Code:    e.responsesErrorCode(),
+				Message: message,
+			},
+		},
+	}
+}
+
+// RenderResponsesStream writes the error as a Responses API stream event: response.failed
+// once the response was created, i.e. responseID is set, and an error event before that.
+// sequenceNumber continues the numbering of the events already sent.
+func (e *NewAPIError) RenderResponsesStream(w io.Writer, responseID string, sequenceNumber int, lang string) error {
+	if e == nil {
+		return nil
+	}
+	var event string
+	var body any
+	if responseID != "" {
+		event, body = "response.failed", e.ToResponsesFailedEvent(responseID, sequenceNumber, lang)
+	} else {
+		event, body = "error", e.ToResponsesErrorEvent(sequenceNumber, lang)
+	}
+	data, err := json.Marshal(body)
+	if err == nil {
+		err = writeSSEEvent(w, event, data)
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return err
+}
@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestToRealtimeErrorEvent verifies the realtime error event shape and event_id correlation
+func TestToRealtimeErrorEvent(t *testing.T) {
+	err := WithOpenAIError(OpenAIError{Message: "Invalid value: 'foo'", Type: "invalid_request_error", Code: NewUpstreamCode("invalid_value"), Param: "session.voice"}, http.StatusBadRequest)
+	event := err.ToRealtimeErrorEvent("event_client_1", "en")
+
+	if event.Type != "error" || !strings.HasPrefix(event.EventID, "event_") {
+		t.Errorf("ToRealtimeErrorEvent() = %+v, want an error event with a server event_id", event)
+	}
+	if event.Error.EventID != "event_client_1" {
+		t.Errorf("ToRealtimeErrorEvent() error.event_id = %q, want %q", event.Error.EventID, "event_client_1")
+	}
+	if event.Error.Type != OpenAIErrorTypeInvalidRequest || event.Error.Param != "session.voice" || event.Error.Message != "Invalid value: 'foo'" {
+		t.Errorf("ToRealtimeErrorEvent() error = %+v", event.Error)
+	}
+	if other := err.ToRealtimeErrorEvent("", "en"); other.EventID == event.EventID {
+		t.Errorf("ToRealtimeErrorEvent() reused event_id %q", event.EventID)
+	}
+}
+
+// TestRealtimeClose verifies which errors end a realtime session and with which close code
+func TestRealtimeClose(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           *NewAPIError
+		expectedClose bool
+		expectedCode  int
+	}{
+		{"Bad client event", NewError(errors.New("bad event"), ErrorCodeInvalidRequest), false, 0},
+		{"Rate limited", WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests), false, 0},
+		{"Token expired", NewError(errors.New("expired"), ErrorCodeTokenExpired), true, WebSocketClosePolicyViolation},
+		{"User quota", NewError(errors.New("no quota"), ErrorCodeInsufficientUserQuota), true, WebSocketClosePolicyViolation},
+		{"Channel failure", NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey), true, WebSocketCloseInternalError},
+		{"Upstream overloaded", WithClaudeError(ClaudeError{Type: ClaudeErrorTypeOverloaded}, StatusUpstreamOverloaded), true, WebSocketCloseTryAgainLater},
+		{"Upstream failure", WithOpenAIError(OpenAIError{Message: "boom", Type: "server_error"}, http.StatusBadGateway), true, WebSocketCloseBadGateway},
+		{"Nil", nil, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closeInfo, ok := tt.err.RealtimeClose()
+			if ok != tt.expectedClose || closeInfo.Code != tt.expectedCode {
+				t.Errorf("RealtimeClose() = %+v, %v, want code %d, %v", closeInfo, ok, tt.expectedCode, tt.expectedClose)
+			}
+			if ok && (closeInfo.Reason == "" || len(closeInfo.Reason) > maxWebSocketCloseReasonLength) {
+				t.Errorf("RealtimeClose() reason = %q", closeInfo.Reason)
+			}
+		})
+	}
+}
+
+// TestRenderResponsesStream verifies the Responses API error and response.failed events
+func TestRenderResponsesStream(t *testing.T) {
+	limited := WithOpenAIError(OpenAIError{Message: "slow down", Type: "requests", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests)
+	tooLong := WithOpenAIError(OpenAIError{Message: "too long", Type: "invalid_request_error", Code: NewUpstreamCode("context_length_exceeded"), Param: "input"}, http.StatusBadRequest)
+	broken := NewError(errors.New("upstream reset"), ErrorCodeUpstreamConnectionReset)
+
+	tests := []struct {
+		name       string
+		err        *NewAPIError
+		responseID string
+		expected   string
+	}{
+		{"Error before response", tooLong, "", "event: error\ndata: {\"type\":\"error\",\"code\":\"context_length_exceeded\",\"message\":\"too long\",\"param\":\"input\",\"sequence_number\":0}\n\n"},
+		{"Failed rate limited", limited, "resp_1", "event: response.failed\ndata: {\"type\":\"response.failed\",\"sequence_number\":7,\"response\":{\"id\":\"resp_1\",\"object\":\"response\",\"status\":\"failed\",\"error\":{\"code\":\"rate_limit_exceeded\",\"message\":\"slow down\"}}}\n\n"},
+		{"Failed server error", broken, "resp_2", "event: response.failed\ndata: {\"type\":\"response.failed\",\"sequence_number\":7,\"response\":{\"id\":\"resp_2\",\"object\":\"response\",\"status\":\"failed\",\"error\":{\"code\":\"server_error\",\"message\":\"Upstream connection was reset\"}}}\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			sequenceNumber := 0
+			if tt.responseID != "" {
+				sequenceNumber = 7
+			}
+			if err := tt.err.RenderResponsesStream(recorder, tt.responseID, sequenceNumber, "en"); err != nil {
+				t.Fatalf("RenderResponsesStream() error = %v", err)
+			}
+			if got := recorder.Body.String(); got != tt.expected {
+				t.Errorf("RenderResponsesStream() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+
+	// RenderStream uses the error event for the Responses format
+	var b strings.Builder
+	if err := limited.RenderStream(&b, RelayFormatOpenAIResponses, "en"); err != nil || !strings.HasPrefix(b.String(), "event: error\n") {
+		t.Errorf("RenderStream(RelayFormatOpenAIResponses) = %q, %v", b.String(), err)
+	}
+	var event ResponsesErrorEvent
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(strings.TrimPrefix(b.String(), "event: error\ndata: "), "\n\n")), &event); err != nil || event.Param != nil {
+		t.Errorf("RenderStream(RelayFormatOpenAIResponses) event = %+v, %v, want param null", event, err)
+	}
+}
@@ -0,0 +1,126 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/QuantumNous/new-api/common"
+)
+
+// ErrorInfo holds all metadata of a registered error code
+type ErrorInfo struct {
+	Code       ErrorCode
+	Name       string
+	HTTPStatus int
+	Category   ErrorCategory // derived from the code range when empty
+	Retry      RetryDecision // default retry decision, RetryDecisionInherit to derive it from the category
+	// Policy overrides, nil to inherit from the category
+	Level           *ErrorLevel
+	ExposeMessage   *bool
+	PenalizeChannel *bool
+	Messages        ErrorMessage
+	Deprecated      bool
+	ReplacedBy      ErrorCode // replacement for a deprecated code, 0 if none
+}
+
+// ErrRegistryFrozen is returned when RegisterError is called after package initialization
+var ErrRegistryFrozen = errors.New("error registry is frozen after initialization")
+
+// The registry is only written during package initialization and frozen afterwards,
+// so lookups need no locking.
+var (
+	errorRegistry       = make(map[ErrorCode]ErrorInfo)
+	errorRegistryByName = make(map[string]ErrorCode)
+	errorRegistryFrozen bool
+)
+
+func init() {
+	for _, info := range builtinErrors {
+		if err := RegisterError(info); err != nil {
+			panic(err)
+		}
+	}
+	errorRegistryFrozen = true
+}
+
+// RegisterError adds an error code to the registry
+// Codes and names must be unique, and the registry only accepts entries during initialization
+func RegisterError(info ErrorInfo) error {
+	if errorRegistryFrozen {
+		return ErrRegistryFrozen
+	}
+	category := info.Code.Category()
+	if category == "" {
+		return fmt.Errorf("error code %d is outside the known ranges", info.Code)
+	}
+	if info.Category == "" {
+		info.Category = category
+	} else if !info.Category.IsValid() {
+		return fmt.Errorf("error code %d has unknown category %q", info.Code, info.Category)
+	} else if info.Category != category {
+		return fmt.Errorf("error code %d belongs to category %q, not %q", info.Code, category, info.Category)
+	}
+	if info.Name == "" {
+		return fmt.Errorf("error code %d has no name", info.Code)
+	}
+	if _, ok := info.Messages["en"]; !ok {
+		return fmt.Errorf("error code %d (%s) has no English message", info.Code, info.Name)
+	}
+	if info.HTTPStatus == 0 {
+		info.HTTPStatus = http.StatusInternalServerError
+	}
+	if existing, ok := errorRegistry[info.Code]; ok {
+		return fmt.Errorf("error code %d already registered as %q", info.Code, existing.Name)
+	}
+	if code, ok := errorRegistryByName[info.Name]; ok {
+		return fmt.Errorf("error name %q already registered for code %d", info.Name, code)
+	}
+	errorRegistry[info.Code] = info.clone()
+	errorRegistryByName[info.Name] = info.Code
+	return nil
+}
+
+// clone returns a deep copy of the info, so callers cannot change the registry through
+// the messages map or the policy overrides
+func (info ErrorInfo) clone() ErrorInfo {
+	if info.Messages != nil {
+		messages := make(ErrorMessage, len(info.Messages))
+		for lang, message := range info.Messages {
+			messages[lang] = message
+		}
+		info.Messages = messages
+	}
+	if info.Level != nil {
+		info.Level = common.GetPointer(*info.Level)
+	}
+	if info.ExposeMessage != nil {
+		info.ExposeMessage = common.GetPointer(*info.ExposeMessage)
+	}
+	if info.PenalizeChannel != nil {
+		info.PenalizeChannel = common.GetPointer(*info.PenalizeChannel)
+	}
+	return info
+}
+
+// GetErrorInfo returns a copy of the registered metadata of an error code
+func GetErrorInfo(code ErrorCode) (ErrorInfo, bool) {
+	info, ok := errorRegistry[code]
+	if !ok {
+		return ErrorInfo{}, false
+	}
+	return info.clone(), true
+}
+
+// ListAllErrors returns copies of all registered error codes sorted by code
+func ListAllErrors() []ErrorInfo {
+	infos := make([]ErrorInfo, 0, len(errorRegistry))
+	for _, info := range errorRegistry {
+		infos = append(infos, info.clone())
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
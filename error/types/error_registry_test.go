@@ -0,0 +1,86 @@
+package types
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestErrorRegistryComplete verifies every registered code has full metadata
+func TestErrorRegistryComplete(t *testing.T) {
+	infos := ListAllErrors()
+	if len(infos) == 0 {
+		t.Fatal("ListAllErrors() returned no errors")
+	}
+
+	for i, info := range infos {
+		if i > 0 && infos[i-1].Code >= info.Code {
+			t.Errorf("ListAllErrors() not sorted at %d", info.Code)
+		}
+		if info.Category == "" {
+			t.Errorf("error code %d has no category", info.Code)
+		}
+		for _, lang := range GetSupportedLanguages() {
+			if info.Messages[lang] == "" {
+				t.Errorf("error code %d (%s) has no %q message", info.Code, info.Name, lang)
+			}
+		}
+		if info.Code.String() != info.Name {
+			t.Errorf("ErrorCode(%d).String() = %q, want %q", info.Code, info.Code.String(), info.Name)
+		}
+		if info.Code.HTTPStatusCode() != info.HTTPStatus {
+			t.Errorf("ErrorCode(%d).HTTPStatusCode() = %d, want %d", info.Code, info.Code.HTTPStatusCode(), info.HTTPStatus)
+		}
+	}
+}
+
+// TestRegisterError verifies duplicate detection and freezing
+func TestRegisterError(t *testing.T) {
+	if err := RegisterError(ErrorInfo{Code: 9999, Name: "late_error", Messages: ErrorMessage{"en": "late"}}); !errors.Is(err, ErrRegistryFrozen) {
+		t.Errorf("RegisterError() after init = %v, want %v", err, ErrRegistryFrozen)
+	}
+
+	errorRegistryFrozen = false
+	defer func() { errorRegistryFrozen = true }()
+
+	tests := []struct {
+		name string
+		info ErrorInfo
+	}{
+		{"Duplicate code", ErrorInfo{Code: ErrorCodeInvalidRequest, Name: "another_name", Messages: ErrorMessage{"en": "x"}}},
+		{"Duplicate name", ErrorInfo{Code: 1999, Name: "invalid_request", Messages: ErrorMessage{"en": "x"}}},
+		{"Out of range", ErrorInfo{Code: 42, Name: "out_of_range", Messages: ErrorMessage{"en": "x"}}},
+		{"Wrong category", ErrorInfo{Code: 1999, Name: "wrong_category", Category: "quota", Messages: ErrorMessage{"en": "x"}}},
+		{"Unknown category", ErrorInfo{Code: 1999, Name: "unknown_category", Category: "bogus", Messages: ErrorMessage{"en": "x"}}},
+		{"Missing English", ErrorInfo{Code: 1999, Name: "no_english", Messages: ErrorMessage{"zh": "x"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := RegisterError(tt.info); err == nil {
+				t.Errorf("RegisterError(%+v) succeeded, want error", tt.info)
+			}
+		})
+	}
+}
+
+// TestGetErrorInfoCopy verifies changes to returned metadata do not reach the registry
+func TestGetErrorInfoCopy(t *testing.T) {
+	info, _ := GetErrorInfo(ErrorCodeCountTokenFailed)
+	expected := info.Messages["en"]
+	info.Messages["en"] = "HACKED"
+	*info.ExposeMessage = false
+
+	listed := ListAllErrors()
+	listed[0].Messages["en"] = "HACKED"
+
+	e := NewError(errors.New("count failed"), ErrorCodeCountTokenFailed)
+	if got := e.Localize("en"); got != expected {
+		t.Errorf("Localize() = %q, want %q", got, expected)
+	}
+	if !ErrorCodeCountTokenFailed.ExposeMessage() {
+		t.Errorf("ExposeMessage() = false, want the registered true")
+	}
+	if got, _ := GetErrorInfo(listed[0].Code); got.Messages["en"] == "HACKED" {
+		t.Errorf("ListAllErrors() shares the messages of code %d with the registry", listed[0].Code)
+	}
+}
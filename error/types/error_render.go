@@ -0,0 +1,101 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// Response headers set on every rendered error, so proxies and logs can classify
+// failures without parsing the body
+const (
+	HeaderErrorCode  = "X-Error-Code"
+	HeaderErrorLevel = "X-Error-Level"
+)
+
+// claudeErrorResponse is Anthropic's error envelope: {"type": "error", "error": {...}}
+type claudeErrorResponse struct {
+	Type  string      `json:"type"`
+	Error ClaudeError `json:"error"`
+}
+
+// openAIErrorResponse is OpenAI's error envelope: {"error": {...}}
+type openAIErrorResponse struct {
+	Error OpenAIError `json:"error"`
+}
+
+// geminiErrorResponse is Google's error envelope: {"error": {...}}
+type geminiErrorResponse struct {
+	Error GeminiError `json:"error"`
+}
+
+// clientMessage returns the message to show instead of the error's own one, and whether
+// to replace it at all. Codes whose policy hides the message get the localized generic text.
+func (e *NewAPIError) clientMessage(lang string) (string, bool) {
+	if e.errorCode.ExposeMessage() {
+		return "", false
+	}
+	return e.Localize(lang), true
+}
+
+// ResponseBody returns the error response body in the envelope the client of the relay
+// format expects. Formats without an error dialect of their own use the OpenAI envelope.
+func (e *NewAPIError) ResponseBody(format RelayFormat, lang string) any {
+	message, replace := e.clientMessage(lang)
+	switch format {
+	case RelayFormatClaude:
+		claudeError := e.ToClaudeError()
+		if replace {
+			claudeError.Message = message
+		}
+		return claudeErrorResponse{Type: "error", Error: claudeError}
+	case RelayFormatGemini:
+		geminiError := e.ToGeminiError()
+		if replace {
+			geminiError.Message = message
+		}
+		return geminiErrorResponse{Error: geminiError}
+	case RelayFormatMjProxy:
+		midjourneyError := e.ToMidjourneyError()
+		if replace {
+			midjourneyError.Description = message
+		}
+		return midjourneyError
+	case RelayFormatRerank:
+		rerankError := e.ToRerankError()
+		if replace {
+			rerankError.Message = message
+		}
+		return rerankError
+	default:
+		openAIError := e.ToOpenAIError()
+		if replace {
+			openAIError.Message = message
+		}
+		return openAIErrorResponse{Error: openAIError}
+	}
+}
+
+// Render writes the error as a complete JSON response for the relay format: status code,
+// content type, the X-Error-Code and X-Error-Level headers and the format's envelope.
+// Messages of codes that must not be exposed are replaced with the message localized to lang.
+func (e *NewAPIError) Render(w http.ResponseWriter, format RelayFormat, lang string) {
+	if e == nil {
+		return
+	}
+	body, err := json.Marshal(e.ResponseBody(format, lang))
+	if err != nil {
+		// the envelopes only hold strings and numbers, but never answer with a broken body
+		body = []byte(`{"error":{"message":"internal error","type":"new_api_error"}}`)
+	}
+	statusCode := e.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusInternalServerError
+	}
+	header := w.Header()
+	header.Set("Content-Type", "application/json; charset=utf-8")
+	header.Set(HeaderErrorCode, strconv.Itoa(int(e.errorCode)))
+	header.Set(HeaderErrorLevel, e.Level.String())
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
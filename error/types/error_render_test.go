@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestRenderEnvelopes verifies every relay format gets its own error envelope
+func TestRenderEnvelopes(t *testing.T) {
+	limited := WithOpenAIError(OpenAIError{Message: "slow down", Type: "requests", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests)
+
+	tests := []struct {
+		format       RelayFormat
+		expectedBody string
+	}{
+		{RelayFormatOpenAI, `{"error":{"message":"slow down","type":"rate_limit_error","param":"","code":"rate_limit_exceeded","numeric_code":5009}}`},
+		{RelayFormatOpenAIResponses, `{"error":{"message":"slow down","type":"rate_limit_error","param":"","code":"rate_limit_exceeded","numeric_code":5009}}`},
+		{RelayFormatClaude, `{"type":"error","error":{"type":"rate_limit_error","message":"slow down"}}`},
+		{RelayFormatGemini, `{"error":{"code":429,"message":"slow down","status":"RESOURCE_EXHAUSTED"}}`},
+		{RelayFormatMjProxy, `{"code":23,"description":"slow down","result":""}`},
+		{RelayFormatRerank, `{"message":"slow down","type":"rate_limit_error","code":"rate_limit_exceeded"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			limited.Render(recorder, tt.format, "en")
+
+			if recorder.Code != http.StatusTooManyRequests {
+				t.Errorf("Render() status = %d, want %d", recorder.Code, http.StatusTooManyRequests)
+			}
+			if got := recorder.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+				t.Errorf("Render() Content-Type = %q", got)
+			}
+			if got := recorder.Header().Get(HeaderErrorCode); got != "5009" {
+				t.Errorf("Render() %s = %q, want %q", HeaderErrorCode, got, "5009")
+			}
+			if got := recorder.Header().Get(HeaderErrorLevel); got != limited.Level.String() {
+				t.Errorf("Render() %s = %q, want %q", HeaderErrorLevel, got, limited.Level.String())
+			}
+			assertJSONEqual(t, recorder.Body.Bytes(), tt.expectedBody)
+		})
+	}
+}
+
+// TestRenderHidesMessage verifies messages of codes that must not be exposed are localized
+func TestRenderHidesMessage(t *testing.T) {
+	leaky := NewError(errors.New("dial tcp 10.0.0.5:443: key sk-abc rejected"), ErrorCodeChannelInvalidKey)
+	info, _ := GetErrorInfo(ErrorCodeChannelInvalidKey)
+
+	tests := []struct {
+		lang     string
+		expected string
+	}{
+		{"zh", info.Messages["zh"]},
+		{"de", info.Messages["en"]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lang, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			leaky.Render(recorder, RelayFormatClaude, tt.lang)
+
+			var body claudeErrorResponse
+			if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Render() body is not JSON: %v", err)
+			}
+			if body.Error.Message != tt.expected {
+				t.Errorf("Render() message = %q, want %q", body.Error.Message, tt.expected)
+			}
+		})
+	}
+
+	// exposed codes keep their own message
+	recorder := httptest.NewRecorder()
+	NewError(errors.New("messages must not be empty"), ErrorCodeInvalidRequest).Render(recorder, RelayFormatOpenAI, "zh")
+	var body openAIErrorResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error.Message != "messages must not be empty" {
+		t.Errorf("Render() message = %q, want the original message", body.Error.Message)
+	}
+
+	recorder = httptest.NewRecorder()
+	NewError(errors.New("unsupported content type: input_audio"), ErrorCodeCountTokenFailed).Render(recorder, RelayFormatOpenAI, "zh")
+	if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error.Message != "unsupported content type: input_audio" {
+		t.Errorf("Render() message = %q, want the original message", body.Error.Message)
+	}
+}
+
+func assertJSONEqual(t *testing.T, got []byte, expected string) {
+	t.Helper()
+	var gotValue, expectedValue any
+	if err := json.Unmarshal(got, &gotValue); err != nil {
+		t.Fatalf("body %s is not JSON: %v", got, err)
+	}
+	if err := json.Unmarshal([]byte(expected), &expectedValue); err != nil {
+		t.Fatalf("expected %s is not JSON: %v", expected, err)
+	}
+	gotJSON, _ := json.Marshal(gotValue)
+	expectedJSON, _ := json.Marshal(expectedValue)
+	if string(gotJSON) != string(expectedJSON) {
+		t.Errorf("body = %s, want %s", gotJSON, expectedJSON)
+	}
+}
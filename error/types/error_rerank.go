@@ -0,0 +1,174 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/QuantumNous/new-api/common"
+)
+
+// maxUpstreamMessageLength caps messages taken verbatim from unstructured upstream bodies
+const maxUpstreamMessageLength = 512
+
+// RerankError is the error body returned to rerank clients: {"message": "...", "type": "...", "code": "..."}
+type RerankError struct {
+	Message string `json:"message"`
+	Type    string `json:"type,omitempty"`
+	Code    string `json:"code,omitempty"`
+}
+
+// rerankErrorBody accepts every error shape returned by rerank providers:
+//   - Cohere: {"message": "..."}
+//   - Jina: {"detail": "..."} or {"detail": [{"msg": "..."}]}
+//   - OpenAI compatible: {"error": {"message": "...", "type": "...", "code": ...}}
+//   - Self-hosted (TEI): {"error": "...", "error_type": "..."}
+type rerankErrorBody struct {
+	Message   string          `json:"message"`
+	Type      string          `json:"type"`
+	Code      UpstreamCode    `json:"code"`
+	Detail    json.RawMessage `json:"detail"`
+	Error     json.RawMessage `json:"error"`
+	ErrorType string          `json:"error_type"`
+}
+
+func (b rerankErrorBody) toRerankError() RerankError {
+	result := RerankError{
+		Message: b.Message,
+		Type:    b.Type,
+		Code:    b.Code.String(),
+	}
+	if result.Type == "" {
+		result.Type = b.ErrorType
+	}
+	if result.Message == "" && len(b.Detail) > 0 {
+		result.Message = rerankDetailMessage(b.Detail)
+	}
+	if len(b.Error) > 0 {
+		var message string
+		var nested rerankErrorBody
+		if err := json.Unmarshal(b.Error, &message); err == nil {
+			if result.Message == "" {
+				result.Message = message
+			}
+		} else if err := json.Unmarshal(b.Error, &nested); err == nil {
+			inner := nested.toRerankError()
+			if result.Message == "" {
+				result.Message = inner.Message
+			}
+			if result.Type == "" {
+				result.Type = inner.Type
+			}
+			if result.Code == "" {
+				result.Code = inner.Code
+			}
+		}
+	}
+	return result
+}
+
+// rerankDetailMessage extracts the message from a Jina / FastAPI "detail" field,
+// which is either a string or a list of validation errors
+func rerankDetailMessage(detail json.RawMessage) string {
+	var message string
+	if err := json.Unmarshal(detail, &message); err == nil {
+		return message
+	}
+	var items []struct {
+		Msg string `json:"msg"`
+	}
+	if err := json.Unmarshal(detail, &items); err == nil {
+		messages := make([]string, 0, len(items))
+		for _, item := range items {
+			if item.Msg != "" {
+				messages = append(messages, item.Msg)
+			}
+		}
+		return strings.Join(messages, "; ")
+	}
+	return string(detail)
+}
+
+// truncateUpstreamMessage trims an unstructured upstream body to a loggable message
+func truncateUpstreamMessage(message string) string {
+	return truncateMessage(message, maxUpstreamMessageLength)
+}
+
+// truncateMessage trims a message to at most limit bytes without splitting a UTF-8 sequence
+func truncateMessage(message string, limit int) string {
+	message = strings.TrimSpace(message)
+	if len(message) <= limit {
+		return message
+	}
+	message = message[:limit]
+	for len(message) > 0 && !utf8.ValidString(message) {
+		message = message[:len(message)-1]
+	}
+	return message + "..."
+}
+
+// ParseRerankError parses an upstream rerank error body in any supported shape
+func ParseRerankError(body []byte, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
+	var rerankError RerankError
+	var parsed rerankErrorBody
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		rerankError = parsed.toRerankError()
+	}
+	if rerankError.Message == "" {
+		rerankError.Message = truncateUpstreamMessage(string(body))
+	}
+	if rerankError.Message == "" {
+		rerankError.Message = http.StatusText(statusCode)
+	}
+	return WithRerankError(rerankError, statusCode, ops...)
+}
+
+// WithRerankError builds a NewAPIError from an upstream rerank error. An error body sent
+// with a 2xx (or zero) status is a broken response, so it gets ErrorCodeBadResponseStatusCode
+// and its status instead of passing the success status on to the client.
+func WithRerankError(rerankError RerankError, statusCode int, ops ...NewAPIErrorOptions) *NewAPIError {
+	errorCode, ok := errorCodeFromUpstreamStatus(statusCode)
+	if !ok {
+		errorCode = ErrorCodeBadResponse
+	}
+	if statusCode < http.StatusBadRequest {
+		errorCode = ErrorCodeBadResponseStatusCode
+		statusCode = errorCode.HTTPStatusCode()
+	}
+	upstreamCode := rerankError.Code
+	if upstreamCode == "" {
+		upstreamCode = rerankError.Type
+	}
+	e := &NewAPIError{
+		RelayError:   rerankError,
+		errorType:    ErrorTypeRerankError,
+		StatusCode:   statusCode,
+		Err:          errors.New(rerankError.Message),
+		errorCode:    errorCode,
+		upstreamCode: upstreamCode,
+		Level:        errorCode.DefaultLevel(), // Set default level
+	}
+	for _, op := range ops {
+		op(e)
+	}
+	return e
+}
+
+// ToRerankError renders the error in the rerank body with a fixed vocabulary: the OpenAI
+// error type and our code name, whatever format the upstream answered in
+func (e *NewAPIError) ToRerankError() RerankError {
+	result := RerankError{
+		Message: e.Error(),
+		Type:    openAIErrorTypeFor(e.errorCode, e.StatusCode),
+		Code:    e.errorCode.String(),
+	}
+	if e.errorCode != ErrorCodeCountTokenFailed {
+		result.Message = common.MaskSensitiveInfo(result.Message)
+	}
+	if result.Message == "" {
+		result.Message = string(e.errorType)
+	}
+	return result
+}
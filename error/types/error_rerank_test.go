@@ -0,0 +1,71 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestParseRerankError verifies every rerank provider error shape is parsed
+func TestParseRerankError(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		statusCode      int
+		expectedMessage string
+		expectedCode    ErrorCode
+	}{
+		{"Cohere", `{"message":"invalid request: query is required"}`, http.StatusBadRequest, "invalid request: query is required", ErrorCodeInvalidRequest},
+		{"Jina string detail", `{"detail":"Invalid API key"}`, http.StatusUnauthorized, "Invalid API key", ErrorCodeChannelInvalidKey},
+		{"Jina validation detail", `{"detail":[{"loc":["body","query"],"msg":"field required"},{"msg":"bad top_n"}]}`, http.StatusUnprocessableEntity, "field required; bad top_n", ErrorCodeInvalidRequest},
+		{"OpenAI compatible", `{"error":{"message":"Rate limit reached","type":"rate_limit_error","code":429}}`, http.StatusTooManyRequests, "Rate limit reached", ErrorCodeRateLimitExceeded},
+		{"TEI", `{"error":"Input validation error","error_type":"Validation"}`, http.StatusRequestEntityTooLarge, "Input validation error", ErrorCodeRequestTooLarge},
+		{"Plain text", `upstream connect error`, http.StatusBadGateway, "upstream connect error", ErrorCodeBadResponse},
+		{"Empty body", ``, http.StatusServiceUnavailable, "Service Unavailable", ErrorCodeServiceUnavailable},
+		{"Error with 200", `{"message":"model not loaded"}`, http.StatusOK, "model not loaded", ErrorCodeBadResponseStatusCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := ParseRerankError([]byte(tt.body), tt.statusCode)
+			if e.Error() != tt.expectedMessage {
+				t.Errorf("ParseRerankError() message = %q, want %q", e.Error(), tt.expectedMessage)
+			}
+			if e.GetErrorCode() != tt.expectedCode {
+				t.Errorf("ParseRerankError() errorCode = %d, want %d", e.GetErrorCode(), tt.expectedCode)
+			}
+			if e.GetErrorType() != ErrorTypeRerankError {
+				t.Errorf("ParseRerankError() errorType = %v, want %v", e.GetErrorType(), ErrorTypeRerankError)
+			}
+		})
+	}
+}
+
+// TestToRerankError verifies rerank clients get the same type vocabulary whatever the error's origin
+func TestToRerankError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *NewAPIError
+		expected RerankError
+	}{
+		{"Rerank upstream", ParseRerankError([]byte(`{"message":"too many documents","type":"Validation"}`), http.StatusBadRequest),
+			RerankError{Message: "too many documents", Type: OpenAIErrorTypeInvalidRequest, Code: "invalid_request"}},
+		{"OpenAI upstream", WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests),
+			RerankError{Message: "slow down", Type: OpenAIErrorTypeRateLimit, Code: "rate_limit_exceeded"}},
+		{"Our own error", NewError(errors.New("no quota"), ErrorCodeInsufficientUserQuota),
+			RerankError{Message: "no quota", Type: OpenAIErrorTypeInsufficientQuota, Code: "insufficient_user_quota"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := tt.err.ToRerankError(); result != tt.expected {
+				t.Errorf("ToRerankError() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	e := ParseRerankError([]byte(`{"message":"model not loaded"}`), http.StatusOK)
+	if e.StatusCode != http.StatusBadGateway {
+		t.Errorf("ParseRerankError() with 200 StatusCode = %d, want %d", e.StatusCode, http.StatusBadGateway)
+	}
+}
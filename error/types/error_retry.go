@@ -0,0 +1,205 @@
+package types
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryDecision tells the relay retry loop what to do after an error
+type RetryDecision int
+
+const (
+	// RetryDecisionInherit is the zero value: the decision is taken from the error code,
+	// or for an ErrorInfo, from its category
+	RetryDecisionInherit RetryDecision = iota
+
+	// RetryDecisionNone means the request must not be retried
+	RetryDecisionNone
+
+	// RetryDecisionSameChannel means the request may be retried on the same channel
+	RetryDecisionSameChannel
+
+	// RetryDecisionOtherChannel means the request may be retried on a different channel
+	RetryDecisionOtherChannel
+)
+
+// String returns the string representation of the retry decision
+func (d RetryDecision) String() string {
+	switch d {
+	case RetryDecisionInherit:
+		return "inherit"
+	case RetryDecisionNone:
+		return "none"
+	case RetryDecisionSameChannel:
+		return "same_channel"
+	case RetryDecisionOtherChannel:
+		return "other_channel"
+	default:
+		return "unknown"
+	}
+}
+
+// defaultSameChannelBackoff is the suggested wait before retrying on the same channel
+// when the upstream gave no hint
+const defaultSameChannelBackoff = time.Second
+
+// RetryAdvice is the retry recommendation for an error
+type RetryAdvice struct {
+	Decision RetryDecision
+	Backoff  time.Duration // suggested wait before the next attempt
+	RetryAt  time.Time     // earliest retry time announced by the upstream, zero if none
+}
+
+// DefaultRetry returns the default retry decision for the error code,
+// falling back to the policy of its category
+func (c ErrorCode) DefaultRetry() RetryDecision {
+	if info, ok := errorRegistry[c]; ok && info.Retry != RetryDecisionInherit {
+		return info.Retry
+	}
+	return c.Category().Policy().Retry
+}
+
+// RetryAdvice returns the retry recommendation for the error. Options take precedence over
+// the default of the error code, and ErrOptionWithSkipRetry always means no retry.
+func (e *NewAPIError) RetryAdvice() RetryAdvice {
+	if e == nil {
+		return RetryAdvice{Decision: RetryDecisionNone}
+	}
+	advice := e.retry
+	if e.skipRetry {
+		advice.Decision = RetryDecisionNone
+	} else if advice.Decision == RetryDecisionInherit {
+		advice.Decision = e.errorCode.DefaultRetry()
+	}
+	if advice.Decision == RetryDecisionNone {
+		advice.Backoff = 0
+		return advice
+	}
+	if advice.Backoff == 0 {
+		if !advice.RetryAt.IsZero() {
+			if wait := time.Until(advice.RetryAt); wait > 0 {
+				advice.Backoff = wait
+			}
+		} else if advice.Decision == RetryDecisionSameChannel {
+			advice.Backoff = defaultSameChannelBackoff
+		}
+	}
+	return advice
+}
+
+// ErrOptionWithRetryDecision overrides the default retry decision of the error code
+func ErrOptionWithRetryDecision(decision RetryDecision) NewAPIErrorOptions {
+	return func(e *NewAPIError) {
+		e.retry.Decision = decision
+	}
+}
+
+// ErrOptionWithRetryBackoff sets the suggested wait before the next attempt
+func ErrOptionWithRetryBackoff(backoff time.Duration) NewAPIErrorOptions {
+	return func(e *NewAPIError) {
+		e.retry.Backoff = backoff
+	}
+}
+
+// ErrOptionWithRetryHeaders sets the retry time announced in upstream response headers
+func ErrOptionWithRetryHeaders(header http.Header) NewAPIErrorOptions {
+	return func(e *NewAPIError) {
+		if retryAt, ok := parseRetryHeaders(header, time.Now()); ok {
+			e.retry.RetryAt = retryAt
+		}
+	}
+}
+
+// rateLimitResetHeaders are the rate limit reset headers of OpenAI-compatible upstreams
+var rateLimitResetHeaders = []string{
+	"X-Ratelimit-Reset-Requests",
+	"X-Ratelimit-Reset-Tokens",
+	"X-Ratelimit-Reset",
+}
+
+// parseRetryHeaders returns the retry time announced by the upstream. Retry-After wins;
+// otherwise the latest x-ratelimit-reset-* deadline is used, since every exhausted limit
+// has to recover before a retry can succeed.
+func parseRetryHeaders(header http.Header, now time.Time) (time.Time, bool) {
+	if header == nil {
+		return time.Time{}, false
+	}
+	if value := header.Get("Retry-After"); value != "" {
+		if retryAt, ok := parseRetryAfter(value, now); ok {
+			return retryAt, true
+		}
+	}
+	var latest time.Time
+	for _, name := range rateLimitResetHeaders {
+		value := header.Get(name)
+		if value == "" {
+			continue
+		}
+		if retryAt, ok := parseRateLimitReset(value, now); ok && retryAt.After(latest) {
+			latest = retryAt
+		}
+	}
+	return latest, !latest.IsZero()
+}
+
+// maxRetryDelay caps the retry delay an upstream can announce, so a broken header cannot
+// postpone retries for years
+const maxRetryDelay = 24 * time.Hour
+
+// capRetryAt limits retryAt to maxRetryDelay after now
+func capRetryAt(retryAt time.Time, now time.Time) time.Time {
+	if limit := now.Add(maxRetryDelay); retryAt.After(limit) {
+		return limit
+	}
+	return retryAt
+}
+
+// retryAfterSeconds returns the time the given delay seconds after now, capped at maxRetryDelay
+func retryAfterSeconds(seconds float64, now time.Time) time.Time {
+	if seconds > maxRetryDelay.Seconds() {
+		return now.Add(maxRetryDelay)
+	}
+	return now.Add(time.Duration(seconds * float64(time.Second)))
+}
+
+// parseRetryAfter parses a Retry-After value, either delay seconds or an HTTP date.
+// RFC 9110 only allows a non-negative integer number of seconds.
+func parseRetryAfter(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseUint(value, 10, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		if err != nil {
+			// too large for any integer, so certainly beyond the cap
+			return now.Add(maxRetryDelay), true
+		}
+		return retryAfterSeconds(float64(seconds), now), true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return capRetryAt(date, now), true
+	}
+	return time.Time{}, false
+}
+
+// parseRateLimitReset parses an x-ratelimit-reset-* value. OpenAI sends durations such as
+// "6m0s" or "20ms", others send delay seconds, a unix timestamp or an RFC 3339 time.
+func parseRateLimitReset(value string, now time.Time) (time.Time, bool) {
+	value = strings.TrimSpace(value)
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return capRetryAt(now.Add(d), now), true
+	}
+	// NaN fails every comparison, so it is rejected together with infinities and negatives
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 && !math.IsInf(seconds, 0) {
+		// values that large can only be a unix timestamp, not a delay
+		if seconds > 1e9 {
+			return retryAfterSeconds(seconds-float64(now.Unix()), now.Truncate(time.Second)), true
+		}
+		return retryAfterSeconds(seconds, now), true
+	}
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return capRetryAt(date, now), true
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestDefaultRetry verifies explicit and category-derived retry decisions
+func TestDefaultRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     ErrorCode
+		expected RetryDecision
+	}{
+		{"Invalid request", ErrorCodeInvalidRequest, RetryDecisionNone},
+		{"Count token failed", ErrorCodeCountTokenFailed, RetryDecisionNone},
+		{"Connection refused", ErrorCodeUpstreamConnectionRefused, RetryDecisionOtherChannel},
+		{"Connection reset", ErrorCodeUpstreamConnectionReset, RetryDecisionSameChannel},
+		{"Channel invalid key", ErrorCodeChannelInvalidKey, RetryDecisionOtherChannel},
+		{"Client closed", ErrorCodeClientClosedRequest, RetryDecisionNone},
+		{"Rate limit", ErrorCodeRateLimitExceeded, RetryDecisionOtherChannel},
+		{"Context length", ErrorCodeContextLengthExceeded, RetryDecisionNone},
+		{"Database", ErrorCodeQueryDataError, RetryDecisionNone},
+		{"User quota", ErrorCodeInsufficientUserQuota, RetryDecisionNone},
+		{"Unregistered", ErrorCode(9999), RetryDecisionNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.code.DefaultRetry(); got != tt.expected {
+				t.Errorf("ErrorCode(%d).DefaultRetry() = %s, want %s", tt.code, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestRetryAdvice verifies options, skipRetry and header hints combine into the advice
+func TestRetryAdvice(t *testing.T) {
+	advice := NewError(errors.New("reset"), ErrorCodeUpstreamConnectionReset).RetryAdvice()
+	if advice.Decision != RetryDecisionSameChannel || advice.Backoff != defaultSameChannelBackoff {
+		t.Errorf("RetryAdvice() = %+v, want same channel with default backoff", advice)
+	}
+
+	skipped := NewError(errors.New("reset"), ErrorCodeUpstreamConnectionReset, ErrOptionWithSkipRetry()).RetryAdvice()
+	if skipped.Decision != RetryDecisionNone || skipped.Backoff != 0 {
+		t.Errorf("RetryAdvice() with skip retry = %+v, want none", skipped)
+	}
+
+	overridden := NewError(errors.New("bad"), ErrorCodeInvalidRequest,
+		ErrOptionWithRetryDecision(RetryDecisionSameChannel), ErrOptionWithRetryBackoff(3*time.Second)).RetryAdvice()
+	if overridden.Decision != RetryDecisionSameChannel || overridden.Backoff != 3*time.Second {
+		t.Errorf("RetryAdvice() with options = %+v, want same channel after 3s", overridden)
+	}
+
+	if e := NewError(errors.New("bad"), ErrorCodeBadResponse, ErrOptionWithRetryDecision(RetryDecisionNone)); !IsSkipRetryError(e) {
+		t.Errorf("IsSkipRetryError() = false for RetryDecisionNone, want true")
+	}
+	// only explicit options skip retries; the defaults of the code are left to RetryAdvice
+	for _, e := range []*NewAPIError{
+		NewError(errors.New("marshal failed"), ErrorCodeJsonMarshalFailed),
+		NewError(errors.New("too long"), ErrorCodeContextLengthExceeded),
+		ParseUpstreamError(http.StatusForbidden, http.Header{}, []byte(`{"error":{"message":"no access","type":"permission_error"}}`)),
+	} {
+		if IsSkipRetryError(e) {
+			t.Errorf("IsSkipRetryError(%v) = true without an option, want false", e.GetErrorCode())
+		}
+	}
+	if advice := ParseUpstreamError(http.StatusForbidden, http.Header{}, nil).RetryAdvice(); advice.Decision != RetryDecisionOtherChannel {
+		t.Errorf("RetryAdvice() for an upstream 403 = %s, want %s", advice.Decision, RetryDecisionOtherChannel)
+	}
+
+	header := http.Header{"Retry-After": {"30"}}
+	limited := ParseUpstreamError(http.StatusTooManyRequests, header, []byte(`{"error":{"message":"slow down","code":"rate_limit_exceeded"}}`)).RetryAdvice()
+	if limited.Decision != RetryDecisionOtherChannel {
+		t.Errorf("RetryAdvice() Decision = %s, want %s", limited.Decision, RetryDecisionOtherChannel)
+	}
+	if limited.RetryAt.IsZero() || limited.Backoff <= 25*time.Second || limited.Backoff > 30*time.Second {
+		t.Errorf("RetryAdvice() = %+v, want a retry in about 30s", limited)
+	}
+
+	if advice := (*NewAPIError)(nil).RetryAdvice(); advice.Decision != RetryDecisionNone {
+		t.Errorf("nil RetryAdvice() Decision = %s, want none", advice.Decision)
+	}
+}
+
+// TestParseRetryHeaders verifies Retry-After and x-ratelimit-reset-* parsing
+func TestParseRetryHeaders(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		header   http.Header
+		expected time.Time
+		ok       bool
+	}{
+		{"No headers", http.Header{}, time.Time{}, false},
+		{"Retry-After seconds", http.Header{"Retry-After": {"120"}}, now.Add(2 * time.Minute), true},
+		{"Retry-After date", http.Header{"Retry-After": {"Sun, 01 Jun 2025 12:05:00 GMT"}}, now.Add(5 * time.Minute), true},
+		{"Retry-After wins", http.Header{"Retry-After": {"1"}, "X-Ratelimit-Reset-Tokens": {"6m0s"}}, now.Add(time.Second), true},
+		{"OpenAI durations take the latest", http.Header{"X-Ratelimit-Reset-Requests": {"20ms"}, "X-Ratelimit-Reset-Tokens": {"1m30s"}}, now.Add(90 * time.Second), true},
+		{"Reset seconds", http.Header{"X-Ratelimit-Reset": {"15"}}, now.Add(15 * time.Second), true},
+		{"Reset unix timestamp", http.Header{"X-Ratelimit-Reset": {"1748779260"}}, time.Unix(1748779260, 0), true},
+		{"Reset RFC 3339", http.Header{"X-Ratelimit-Reset": {"2025-06-01T12:00:45Z"}}, now.Add(45 * time.Second), true},
+		{"Garbage", http.Header{"Retry-After": {"soon"}, "X-Ratelimit-Reset-Requests": {"later"}}, time.Time{}, false},
+		{"Retry-After NaN", http.Header{"Retry-After": {"NaN"}}, time.Time{}, false},
+		{"Retry-After fraction", http.Header{"Retry-After": {"1.5"}}, time.Time{}, false},
+		{"Retry-After exponent", http.Header{"Retry-After": {"1e30"}}, time.Time{}, false},
+		{"Retry-After negative", http.Header{"Retry-After": {"-5"}}, time.Time{}, false},
+		{"Retry-After capped", http.Header{"Retry-After": {"999999999"}}, now.Add(maxRetryDelay), true},
+		{"Retry-After overflow capped", http.Header{"Retry-After": {"99999999999999999999999"}}, now.Add(maxRetryDelay), true},
+		{"Retry-After date capped", http.Header{"Retry-After": {"Fri, 01 Jun 2125 12:00:00 GMT"}}, now.Add(maxRetryDelay), true},
+		{"Reset Inf", http.Header{"X-Ratelimit-Reset": {"Inf"}}, time.Time{}, false},
+		{"Reset NaN", http.Header{"X-Ratelimit-Reset": {"NaN"}}, time.Time{}, false},
+		{"Reset overflow", http.Header{"X-Ratelimit-Reset": {"1e400"}}, time.Time{}, false},
+		{"Reset huge timestamp capped", http.Header{"X-Ratelimit-Reset": {"1e30"}}, now.Add(maxRetryDelay), true},
+		{"Reset duration capped", http.Header{"X-Ratelimit-Reset-Tokens": {"2000000h"}}, now.Add(maxRetryDelay), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryHeaders(tt.header, now)
+			if ok != tt.ok || !got.Equal(tt.expected) {
+				t.Errorf("parseRetryHeaders() = %v, %v, want %v, %v", got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
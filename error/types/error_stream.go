@@ -0,0 +1,91 @@
+package types
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// StreamProgress records how much of a streamed response reached the client before it failed,
+// so billing can charge partial streams correctly
+type StreamProgress struct {
+	Chunks int `json:"chunks"` // data chunks already written to the client
+	Tokens int `json:"tokens"` // completion tokens already delivered
+}
+
+// ErrOptionWithStreamProgress records the part of the stream delivered before the error
+func ErrOptionWithStreamProgress(progress StreamProgress) NewAPIErrorOptions {
+	return func(e *NewAPIError) {
+		e.streamProgress = &progress
+	}
+}
+
+// StreamProgress returns the delivered part of the stream, and false if the error did not
+// happen mid-stream
+func (e *NewAPIError) StreamProgress() (StreamProgress, bool) {
+	if e == nil || e.streamProgress == nil {
+		return StreamProgress{}, false
+	}
+	return *e.streamProgress, true
+}
+
+// writeSSEEvent writes one server-sent event; the event name is omitted when empty
+func writeSSEEvent(w io.Writer, event string, data []byte) error {
+	if event != "" {
+		if _, err := io.WriteString(w, "event: "+event+"\n"); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, "data: "); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n\n")
+	return err
+}
+
+// RenderStream writes the error as an in-stream event for a response whose status and
+// headers were already sent:
+//   - OpenAI: data: {"error": {...}} followed by data: [DONE]
+//   - Claude: event: error with data: {"type": "error", "error": {...}}
+//   - Gemini: a data chunk holding {"error": {...}}
+//   - Responses API: an error event numbered 0
+//
+// Responses API streams that already sent events must use RenderResponsesStream instead,
+// which fails the response and continues the sequence numbers. Formats without a stream
+// dialect of their own use the OpenAI events. Messages are localized like in Render.
+// The writer is flushed if it supports http.Flusher.
+func (e *NewAPIError) RenderStream(w io.Writer, format RelayFormat, lang string) error {
+	if e == nil {
+		return nil
+	}
+	var err error
+	switch format {
+	case RelayFormatOpenAIResponses:
+		return e.RenderResponsesStream(w, "", 0, lang)
+	case RelayFormatClaude:
+		err = e.writeStreamEvent(w, "error", format, lang)
+	case RelayFormatGemini:
+		err = e.writeStreamEvent(w, "", format, lang)
+	default:
+		err = e.writeStreamEvent(w, "", RelayFormatOpenAI, lang)
+		if err == nil {
+			err = writeSSEEvent(w, "", []byte("[DONE]"))
+		}
+	}
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return err
+}
+
+// writeStreamEvent writes the response body of the format as one event
+func (e *NewAPIError) writeStreamEvent(w io.Writer, event string, format RelayFormat, lang string) error {
+	data, err := json.Marshal(e.ResponseBody(format, lang))
+	if err != nil {
+		return err
+	}
+	return writeSSEEvent(w, event, data)
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestRenderStream verifies every relay format gets a protocol-correct in-stream error
+func TestRenderStream(t *testing.T) {
+	overloaded := WithClaudeError(ClaudeError{Type: ClaudeErrorTypeOverloaded, Message: "Overloaded"}, StatusUpstreamOverloaded)
+
+	tests := []struct {
+		format   RelayFormat
+		expected string
+	}{
+		{RelayFormatOpenAI, "data: {\"error\":{\"message\":\"Overloaded\",\"type\":\"server_error\",\"param\":\"\",\"code\":\"upstream_overloaded\",\"numeric_code\":5014}}\n\ndata: [DONE]\n\n"},
+		{RelayFormatClaude, "event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"},
+		{RelayFormatGemini, "data: {\"error\":{\"code\":529,\"message\":\"Overloaded\",\"status\":\"INTERNAL\"}}\n\n"},
+		{RelayFormatMjProxy, "data: {\"error\":{\"message\":\"Overloaded\",\"type\":\"server_error\",\"param\":\"\",\"code\":\"upstream_overloaded\",\"numeric_code\":5014}}\n\ndata: [DONE]\n\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if err := overloaded.RenderStream(recorder, tt.format, "en"); err != nil {
+				t.Fatalf("RenderStream() error = %v", err)
+			}
+			if got := recorder.Body.String(); got != tt.expected {
+				t.Errorf("RenderStream() = %q, want %q", got, tt.expected)
+			}
+			if !recorder.Flushed {
+				t.Errorf("RenderStream() did not flush the writer")
+			}
+		})
+	}
+}
+
+// TestRenderStreamHidesMessage verifies in-stream errors localize hidden messages like Render
+func TestRenderStreamHidesMessage(t *testing.T) {
+	var b strings.Builder
+	err := NewError(errors.New("key sk-abc rejected"), ErrorCodeChannelInvalidKey)
+	if renderErr := err.RenderStream(&b, RelayFormatOpenAI, "en"); renderErr != nil {
+		t.Fatalf("RenderStream() error = %v", renderErr)
+	}
+	if strings.Contains(b.String(), "sk-abc") {
+		t.Errorf("RenderStream() = %q, leaked the original message", b.String())
+	}
+}
+
+// TestStreamProgress verifies the delivered part of the stream is kept on the error
+func TestStreamProgress(t *testing.T) {
+	err := NewError(errors.New("stream broke"), ErrorCodeBadResponse)
+	if _, ok := err.StreamProgress(); ok {
+		t.Errorf("StreamProgress() ok = true for an error without progress")
+	}
+
+	partial := NewError(err, ErrorCodeBadResponse, ErrOptionWithStreamProgress(StreamProgress{Chunks: 12, Tokens: 340}))
+	progress, ok := partial.StreamProgress()
+	if !ok || progress.Chunks != 12 || progress.Tokens != 340 {
+		t.Errorf("StreamProgress() = %+v, %v, want {12 340}, true", progress, ok)
+	}
+	if _, ok := err.StreamProgress(); ok {
+		t.Errorf("ErrOptionWithStreamProgress() modified the wrapped error")
+	}
+}
@@ -2,7 +2,9 @@ package types
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"sync"
 	"testing"
 )
 
@@ -43,6 +45,20 @@ func TestErrorCodeSystem(t *testing.T) {
 			expectedLevel:  ErrorLevelCritical,
 			expectedString: "database_connection_failed",
 		},
+		{
+			name:           "TokenExpired",
+			errorCode:      ErrorCodeTokenExpired,
+			expectedHTTP:   http.StatusUnauthorized,
+			expectedLevel:  ErrorLevelWarning,
+			expectedString: "token_expired",
+		},
+		{
+			name:           "TokenModelNotPermitted",
+			errorCode:      ErrorCodeTokenModelNotPermitted,
+			expectedHTTP:   http.StatusForbidden,
+			expectedLevel:  ErrorLevelWarning,
+			expectedString: "token_model_not_permitted",
+		},
 	}
 
 	for _, tt := range tests {
@@ -109,7 +125,11 @@ func TestErrorCodeFromString(t *testing.T) {
 		{"invalid_request", ErrorCodeInvalidRequest},
 		{"channel_no_available_key", ErrorCodeChannelNoAvailableKey},
 		{"insufficient_user_quota", ErrorCodeInsufficientUserQuota},
-		{"unknown_code", ErrorCodeInvalidRequest}, // fallback
+		{"unknown_code", ErrorCodeUnknownUpstream}, // fallback
+		{"insufficient_quota", ErrorCodeUnknownUpstream},
+		{"overloaded_error", ErrorCodeUnknownUpstream},
+		{"null", ErrorCodeUnknownUpstream},
+		{"", ErrorCodeUnknownUpstream},
 	}
 
 	for _, tt := range tests {
@@ -218,3 +238,99 @@ func indexOf(s, substr string) int {
 	}
 	return -1
 }
+
+// TestUpstreamCodePreserved verifies the original upstream code is kept on the error
+func TestUpstreamCodePreserved(t *testing.T) {
+	err := WithOpenAIError(OpenAIError{Message: "quota", Type: "insufficient_quota", Code: NewUpstreamCode("insufficient_quota")}, http.StatusTooManyRequests)
+	if err.GetUpstreamCode() != "insufficient_quota" {
+		t.Errorf("GetUpstreamCode() = %q, want %q", err.GetUpstreamCode(), "insufficient_quota")
+	}
+
+	claudeErr := WithClaudeError(ClaudeError{Type: "overloaded_error", Message: "Overloaded"}, 529)
+	if result := claudeErr.ToOpenAIError(); result.Code.String() != "upstream_overloaded" {
+		t.Errorf("ToOpenAIError() Code = %v, want %q", result.Code, "upstream_overloaded")
+	}
+
+	passthrough := WithClaudeError(ClaudeError{Type: "overloaded_error", Message: "Overloaded"}, 529, ErrOptionWithUpstreamCodePassthrough())
+	if result := passthrough.ToOpenAIError(); result.Code.String() != "overloaded_error" {
+		t.Errorf("ToOpenAIError() Code = %v, want %q", result.Code, "overloaded_error")
+	}
+}
+
+// TestNewErrorDoesNotMutateWrapped verifies options are applied to a copy of a wrapped error
+func TestNewErrorDoesNotMutateWrapped(t *testing.T) {
+	original := NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey)
+	wrapped := NewError(fmt.Errorf("relay failed: %w", original), ErrorCodeDoRequestFailed,
+		ErrOptionWithSkipRetry(), ErrOptionWithStatusCode(http.StatusTooManyRequests), ErrOptionWithNoRecordErrorLog())
+
+	if wrapped == original {
+		t.Fatalf("NewError() returned the wrapped error itself, want a copy")
+	}
+	if IsSkipRetryError(original) || original.StatusCode != http.StatusServiceUnavailable || !IsRecordErrorLog(original) {
+		t.Errorf("NewError() modified the wrapped error: %+v", original)
+	}
+	if !IsSkipRetryError(wrapped) || wrapped.StatusCode != http.StatusTooManyRequests || IsRecordErrorLog(wrapped) {
+		t.Errorf("NewError() did not apply the options to the copy: %+v", wrapped)
+	}
+	if wrapped.GetErrorCode() != ErrorCodeChannelNoAvailableKey {
+		t.Errorf("NewError() errorCode = %d, want %d", wrapped.GetErrorCode(), ErrorCodeChannelNoAvailableKey)
+	}
+	if wrapped.Error() != "relay failed: no key" {
+		t.Errorf("NewError() message = %q, want %q", wrapped.Error(), "relay failed: no key")
+	}
+	var cause *NewAPIError
+	if !errors.As(wrapped.Unwrap(), &cause) || cause != original {
+		t.Errorf("NewError() cause chain does not lead back to the wrapped error")
+	}
+
+	relay := NewOpenAIError(original, ErrorCodeDoRequestFailed, http.StatusBadGateway)
+	if original.RelayError != nil {
+		t.Errorf("NewOpenAIError() set RelayError on the wrapped error")
+	}
+	if _, ok := relay.RelayError.(OpenAIError); !ok {
+		t.Errorf("NewOpenAIError() RelayError = %T, want OpenAIError", relay.RelayError)
+	}
+}
+
+// TestNewErrorConcurrentWrap verifies a shared error can be wrapped from many goroutines; run with -race
+func TestNewErrorConcurrentWrap(t *testing.T) {
+	shared := WithOpenAIError(OpenAIError{Message: "slow down", Code: NewUpstreamCode("rate_limit_exceeded")}, http.StatusTooManyRequests)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			e := NewError(shared, ErrorCodeBadResponse,
+				ErrOptionWithStatusCode(http.StatusBadGateway+i), ErrOptionWithLevel(ErrorLevelCritical), ErrOptionWithHideErrMsg("hidden"))
+			if e.StatusCode != http.StatusBadGateway+i || e.Error() != "hidden" {
+				t.Errorf("NewError() = %d %q, want %d %q", e.StatusCode, e.Error(), http.StatusBadGateway+i, "hidden")
+			}
+			_ = NewOpenAIError(shared, ErrorCodeBadResponse, http.StatusBadGateway, ErrOptionWithSkipRetry()).ToOpenAIError()
+			_ = shared.ToClaudeError()
+		}(i)
+	}
+	wg.Wait()
+
+	if shared.StatusCode != http.StatusTooManyRequests || shared.Error() != "slow down" || IsSkipRetryError(shared) {
+		t.Errorf("shared error was modified: %d %q", shared.StatusCode, shared.Error())
+	}
+}
+
+// TestClone verifies a clone shares no mutable state with the original
+func TestClone(t *testing.T) {
+	original := NewError(errors.New("boom"), ErrorCodeBadResponse, ErrOptionWithNoRecordErrorLog())
+	original.Metadata = []byte(`{"a":1}`)
+
+	clone := original.Clone()
+	*clone.recordErrorLog = true
+	clone.Metadata[1] = 'b'
+	clone.SetMessage("changed")
+
+	if IsRecordErrorLog(original) || string(original.Metadata) != `{"a":1}` || original.Error() != "boom" {
+		t.Errorf("Clone() shares state with the original: %+v", original)
+	}
+	if (*NewAPIError)(nil).Clone() != nil {
+		t.Errorf("nil Clone() should be nil")
+	}
+}
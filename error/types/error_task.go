@@ -0,0 +1,93 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TaskError is the failure of an asynchronous task, such as video or image generation.
+// It is derived from a NewAPIError when the task fails and is stored with the task as JSON,
+// so it only keeps what the task-status endpoint needs to answer later polls.
+type TaskError struct {
+	Code         ErrorCode `json:"code"`
+	StatusCode   int       `json:"status_code"`
+	Message      string    `json:"message"`                 // masked message of the original error
+	UpstreamCode string    `json:"upstream_code,omitempty"` // code reported by the upstream, empty if none
+	Refundable   bool      `json:"refundable"`              // whether the quota charged for the task is returned
+}
+
+// VideoError is the error field of an OpenAI video object: {"code": "...", "message": "..."}
+type VideoError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// taskRefundable reports whether a task that failed with the code should be refunded.
+// Failures caused by the request itself are not: general and client errors, and upstream
+// errors that are neither retried nor held against the channel, such as a rejected prompt
+// or a context that is too long.
+func taskRefundable(code ErrorCode) bool {
+	switch code.Category() {
+	case CategoryGeneral, CategoryClient:
+		return false
+	case CategoryUpstream:
+		return code.DefaultRetry() != RetryDecisionNone || code.PenalizesChannel()
+	}
+	return true
+}
+
+// ToTaskError converts the error to a TaskError. The task is refundable unless the
+// request itself caused the failure; set Refundable to override that.
+func (e *NewAPIError) ToTaskError() *TaskError {
+	if e == nil {
+		return nil
+	}
+	return &TaskError{
+		Code:         e.errorCode,
+		StatusCode:   e.StatusCode,
+		Message:      e.MaskSensitiveError(),
+		UpstreamCode: e.upstreamCode,
+		Refundable:   taskRefundable(e.errorCode),
+	}
+}
+
+// Error implements the error interface
+func (t *TaskError) Error() string {
+	return t.Message
+}
+
+// ToNewAPIError restores a NewAPIError from the stored task failure, e.g. to render it with Render
+func (t *TaskError) ToNewAPIError() *NewAPIError {
+	return NewError(errors.New(t.Message), t.Code, func(e *NewAPIError) {
+		if t.StatusCode != 0 {
+			e.StatusCode = t.StatusCode
+		}
+		e.upstreamCode = t.UpstreamCode
+	})
+}
+
+// Reason returns the failure reason shown to users polling the task. Messages of codes
+// that must not be exposed are replaced with the message localized to lang.
+func (t *TaskError) Reason(lang string) string {
+	if t.Code.ExposeMessage() && t.Message != "" {
+		return t.Message
+	}
+	return t.ToNewAPIError().Localize(lang)
+}
+
+// ToVideoError returns the error field of the OpenAI video object of the failed task
+func (t *TaskError) ToVideoError(lang string) VideoError {
+	return VideoError{
+		Code:    t.Code.String(),
+		Message: t.Reason(lang),
+	}
+}
+
+// FailReason returns the failReason of the midjourney-proxy task object. midjourney-proxy
+// has no code field for failed tasks, so the code name is appended to the reason.
+func (t *TaskError) FailReason(lang string) string {
+	if name := t.Code.String(); name != "" {
+		return fmt.Sprintf("%s (%s)", t.Reason(lang), name)
+	}
+	return t.Reason(lang)
+}
@@ -0,0 +1,84 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestToTaskError verifies task failures keep their code and are refundable unless the request caused them
+func TestToTaskError(t *testing.T) {
+	tests := []struct {
+		name               string
+		err                *NewAPIError
+		expectedCode       ErrorCode
+		expectedRefundable bool
+	}{
+		{"Upstream failure", WithOpenAIError(OpenAIError{Message: "generation failed", Type: "server_error"}, http.StatusInternalServerError), ErrorCodeBadResponse, true},
+		{"Prompt blocked", WithMidjourneyError(MidjourneyError{Code: MidjourneyCodeBannedPrompt, Description: "banned prompt"}, http.StatusBadRequest), ErrorCodePromptBlocked, false},
+		{"Bad request", NewError(errors.New("seconds must be 4, 8 or 12"), ErrorCodeInvalidRequest), ErrorCodeInvalidRequest, false},
+		{"Channel failure", NewError(errors.New("no key"), ErrorCodeChannelNoAvailableKey), ErrorCodeChannelNoAvailableKey, true},
+		{"Context too long", NewError(errors.New("prompt is too long"), ErrorCodeContextLengthExceeded), ErrorCodeContextLengthExceeded, false},
+		{"Request too large", NewError(errors.New("image exceeds 20 MB"), ErrorCodeRequestTooLarge), ErrorCodeRequestTooLarge, false},
+		{"Upstream rate limit", NewError(errors.New("slow down"), ErrorCodeRateLimitExceeded), ErrorCodeRateLimitExceeded, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			taskErr := tt.err.ToTaskError()
+			if taskErr.Code != tt.expectedCode {
+				t.Errorf("ToTaskError() Code = %v, want %v", taskErr.Code, tt.expectedCode)
+			}
+			if taskErr.Refundable != tt.expectedRefundable {
+				t.Errorf("ToTaskError() Refundable = %v, want %v", taskErr.Refundable, tt.expectedRefundable)
+			}
+			if taskErr.StatusCode != tt.err.StatusCode {
+				t.Errorf("ToTaskError() StatusCode = %d, want %d", taskErr.StatusCode, tt.err.StatusCode)
+			}
+		})
+	}
+
+	var nilErr *NewAPIError
+	if nilErr.ToTaskError() != nil {
+		t.Errorf("ToTaskError() on nil = %v, want nil", nilErr.ToTaskError())
+	}
+}
+
+// TestTaskErrorStored verifies a task error survives being stored as JSON and renders for pollers
+func TestTaskErrorStored(t *testing.T) {
+	original := NewError(errors.New("no key for sora-2"), ErrorCodeChannelNoAvailableKey).ToTaskError()
+	data, err := json.Marshal(original)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	var stored TaskError
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if stored != *original {
+		t.Errorf("stored TaskError = %+v, want %+v", stored, *original)
+	}
+
+	info, _ := GetErrorInfo(ErrorCodeChannelNoAvailableKey)
+	videoError := stored.ToVideoError("zh")
+	if videoError.Code != "channel_no_available_key" || videoError.Message != info.Messages["zh"] {
+		t.Errorf("ToVideoError() = %+v, want the code name and the localized message", videoError)
+	}
+	if got, expected := stored.FailReason("en"), info.Messages["en"]+" (channel_no_available_key)"; got != expected {
+		t.Errorf("FailReason() = %q, want %q", got, expected)
+	}
+
+	restored := stored.ToNewAPIError()
+	if restored.GetErrorCode() != ErrorCodeChannelNoAvailableKey || restored.StatusCode != original.StatusCode {
+		t.Errorf("ToNewAPIError() = %v %d, want %v %d", restored.GetErrorCode(), restored.StatusCode, ErrorCodeChannelNoAvailableKey, original.StatusCode)
+	}
+}
+
+// TestTaskErrorReason verifies exposed messages are kept for pollers
+func TestTaskErrorReason(t *testing.T) {
+	taskErr := NewError(errors.New("seconds must be 4, 8 or 12"), ErrorCodeInvalidRequest).ToTaskError()
+	if got := taskErr.Reason("zh"); got != "seconds must be 4, 8 or 12" {
+		t.Errorf("Reason() = %q, want the original message", got)
+	}
+}
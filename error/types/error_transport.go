@@ -0,0 +1,117 @@
+package types
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+)
+
+// ClassifyTransportError builds a NewAPIError for an error returned by http.Client.Do, telling
+// DNS, connection, TLS, proxy, timeout and cancellation failures apart instead of reporting
+// all of them as ErrorCodeDoRequestFailed. Upstream requests run on the inbound request
+// context, so a canceled request means the client went away and is reported as
+// ErrorCodeClientClosedRequest, like in ClassifyRequestError. It returns nil if err is nil.
+// Requests sent through an outbound proxy use ClassifyProxiedTransportError.
+func ClassifyTransportError(err error, ops ...NewAPIErrorOptions) *NewAPIError {
+	return classifyTransportError(err, false, ops...)
+}
+
+// ClassifyProxiedTransportError is ClassifyTransportError for a client with an outbound proxy.
+// net/http reports a proxy that refuses CONNECT only by the status text of its answer, which
+// would be ambiguous for direct requests.
+func ClassifyProxiedTransportError(err error, ops ...NewAPIErrorOptions) *NewAPIError {
+	return classifyTransportError(err, true, ops...)
+}
+
+func classifyTransportError(err error, proxied bool, ops ...NewAPIErrorOptions) *NewAPIError {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.Canceled) {
+		return newClientClosedError(err, ops...)
+	}
+	return NewError(err, transportErrorCode(err, proxied), ops...)
+}
+
+// transportErrorCode maps a transport error to an error code.
+// The checks run from the most to the least specific cause.
+func transportErrorCode(err error, proxied bool) ErrorCode {
+	switch {
+	case isProxyError(err, proxied):
+		return ErrorCodeUpstreamProxyFailed
+	case isDNSError(err):
+		return ErrorCodeUpstreamDNSFailed
+	case isTLSError(err):
+		return ErrorCodeUpstreamTLSFailed
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorCodeUpstreamConnectionRefused
+	case isTimeoutError(err):
+		return ErrorCodeUpstreamTimeout
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorCodeUpstreamConnectionReset
+	default:
+		return ErrorCodeDoRequestFailed
+	}
+}
+
+// isProxyError reports whether the request failed while talking to the outbound proxy
+func isProxyError(err error, proxied bool) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && (opErr.Op == "proxyconnect" || strings.HasPrefix(opErr.Op, "socks")) {
+		return true
+	}
+	if !proxied {
+		return false
+	}
+	// net/http reports a non-200 answer to CONNECT as a bare error holding the status text
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && urlErr.Err != nil && errors.Unwrap(urlErr.Err) == nil {
+		message := urlErr.Err.Error()
+		for code := http.StatusBadRequest; code < 600; code++ {
+			if text := http.StatusText(code); text != "" && text == message {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isDNSError reports whether the upstream host name could not be resolved
+func isDNSError(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// isTLSError reports whether the TLS handshake or certificate verification failed
+func isTLSError(err error) bool {
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certificateInvalidErr x509.CertificateInvalidError
+	var verificationErr *tls.CertificateVerificationError
+	var recordHeaderErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	return errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &certificateInvalidErr) ||
+		errors.As(err, &verificationErr) ||
+		errors.As(err, &recordHeaderErr) ||
+		errors.As(err, &alertErr)
+}
+
+// isTimeoutError reports whether the request ran out of time at any stage
+func isTimeoutError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
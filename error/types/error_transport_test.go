@@ -0,0 +1,153 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// closedAddr returns the address of a local listener that has already been closed
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+	return addr
+}
+
+// TestClassifyTransportError reproduces each transport failure against local listeners
+func TestClassifyTransportError(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer slow.Close()
+
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer tlsServer.Close()
+
+	// a proxy that rejects every CONNECT
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusProxyAuthRequired)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+	deadProxyURL, _ := url.Parse("http://" + closedAddr(t))
+
+	// a server that drops every connection without answering
+	resetListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("net.Listen() error = %v", err)
+	}
+	defer resetListener.Close()
+	go func() {
+		for {
+			conn, err := resetListener.Accept()
+			if err != nil {
+				return
+			}
+			if tcpConn, ok := conn.(*net.TCPConn); ok {
+				tcpConn.SetLinger(0)
+			}
+			conn.Close()
+		}
+	}()
+
+	// a resolver whose DNS server is not listening
+	dnsAddr := closedAddr(t)
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "tcp", dnsAddr)
+		},
+	}
+
+	tests := []struct {
+		name           string
+		transport      *http.Transport
+		url            string
+		timeout        time.Duration
+		cancel         bool
+		expectedCode   ErrorCode
+		expectedSkip   bool
+		expectedStatus int
+	}{
+		{"DNS failure", &http.Transport{DialContext: (&net.Dialer{Resolver: resolver}).DialContext}, "http://upstream.invalid/", 0, false, ErrorCodeUpstreamDNSFailed, false, http.StatusBadGateway},
+		{"Connection refused", &http.Transport{}, "http://" + closedAddr(t) + "/", 0, false, ErrorCodeUpstreamConnectionRefused, false, http.StatusBadGateway},
+		{"TLS untrusted certificate", &http.Transport{}, tlsServer.URL, 0, false, ErrorCodeUpstreamTLSFailed, false, http.StatusBadGateway},
+		{"Proxy rejects CONNECT", &http.Transport{Proxy: http.ProxyURL(proxyURL)}, "https://upstream.invalid/", 0, false, ErrorCodeUpstreamProxyFailed, false, http.StatusBadGateway},
+		{"Proxy down", &http.Transport{Proxy: http.ProxyURL(deadProxyURL)}, "http://upstream.invalid/", 0, false, ErrorCodeUpstreamProxyFailed, false, http.StatusBadGateway},
+		{"Deadline exceeded", &http.Transport{}, slow.URL, 50 * time.Millisecond, false, ErrorCodeUpstreamTimeout, false, http.StatusGatewayTimeout},
+		{"Connection reset", &http.Transport{}, "http://" + resetListener.Addr().String() + "/", 0, false, ErrorCodeUpstreamConnectionReset, false, http.StatusBadGateway},
+		{"Canceled", &http.Transport{}, slow.URL, 0, true, ErrorCodeClientClosedRequest, true, StatusClientClosedRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer tt.transport.CloseIdleConnections()
+			ctx := context.Background()
+			var cancel context.CancelFunc
+			if tt.timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, tt.timeout)
+			} else {
+				ctx, cancel = context.WithCancel(ctx)
+			}
+			defer cancel()
+			if tt.cancel {
+				cancel()
+			}
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, tt.url, nil)
+			resp, err := (&http.Client{Transport: tt.transport}).Do(req)
+			if err == nil {
+				resp.Body.Close()
+				t.Fatalf("Do(%s) succeeded, want a transport error", tt.url)
+			}
+
+			e := ClassifyTransportError(err)
+			if tt.transport.Proxy != nil {
+				e = ClassifyProxiedTransportError(err)
+			}
+			if e.GetErrorCode() != tt.expectedCode {
+				t.Errorf("ClassifyTransportError(%v) errorCode = %d, want %d", err, e.GetErrorCode(), tt.expectedCode)
+			}
+			if e.StatusCode != tt.expectedStatus {
+				t.Errorf("ClassifyTransportError(%v) StatusCode = %d, want %d", err, e.StatusCode, tt.expectedStatus)
+			}
+			if IsSkipRetryError(e) != tt.expectedSkip {
+				t.Errorf("ClassifyTransportError(%v) skipRetry = %v, want %v", err, IsSkipRetryError(e), tt.expectedSkip)
+			}
+			if !errors.Is(e, err) {
+				t.Errorf("ClassifyTransportError(%v) does not wrap the original error", err)
+			}
+		})
+	}
+}
+
+// TestClassifyTransportErrorFallback verifies unknown and nil errors
+func TestClassifyTransportErrorFallback(t *testing.T) {
+	if e := ClassifyTransportError(nil); e != nil {
+		t.Errorf("ClassifyTransportError(nil) = %v, want nil", e)
+	}
+	if e := ClassifyTransportError(errors.New("something else")); e.GetErrorCode() != ErrorCodeDoRequestFailed {
+		t.Errorf("ClassifyTransportError() errorCode = %d, want %d", e.GetErrorCode(), ErrorCodeDoRequestFailed)
+	}
+
+	// a bare status text is only a proxy answer when a proxy is configured
+	statusText := &url.Error{Op: "Post", URL: "https://upstream.example/v1/chat/completions", Err: errors.New("Bad Gateway")}
+	if e := ClassifyTransportError(statusText); e.GetErrorCode() != ErrorCodeDoRequestFailed {
+		t.Errorf("ClassifyTransportError(%v) errorCode = %d, want %d", statusText, e.GetErrorCode(), ErrorCodeDoRequestFailed)
+	}
+	if e := ClassifyProxiedTransportError(statusText); e.GetErrorCode() != ErrorCodeUpstreamProxyFailed {
+		t.Errorf("ClassifyProxiedTransportError(%v) errorCode = %d, want %d", statusText, e.GetErrorCode(), ErrorCodeUpstreamProxyFailed)
+	}
+}
@@ -0,0 +1,223 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html"
+	"net/http"
+	"strings"
+)
+
+// upstreamErrorEnvelope accepts the top level of every supported upstream error body:
+//   - OpenAI: {"error": {"message": "...", "type": "...", "code": ...}} or {"error": "..."}
+//   - Claude: {"type": "error", "error": {"type": "...", "message": "..."}}
+//   - Gemini: {"error": {"code": 429, "message": "...", "status": "RESOURCE_EXHAUSTED"}}
+//   - Midjourney: {"code": 23, "description": "...", "result": ""}
+//   - Responses API stream: {"type": "error", "code": "...", "message": "...", "sequence_number": 3}
+//     or {"type": "response.failed", "response": {"error": {"code": "...", "message": "..."}}}
+//   - Generic: {"message": "..."} or {"detail": "..."}
+type upstreamErrorEnvelope struct {
+	Type        string          `json:"type"`
+	Error       json.RawMessage `json:"error"`
+	Code        UpstreamCode    `json:"code"`
+	Description *string         `json:"description"`
+	Message     string          `json:"message"`
+	Detail      json.RawMessage `json:"detail"`
+	Response    *struct {
+		Error *upstreamErrorObject `json:"error"`
+	} `json:"response"`
+}
+
+// upstreamErrorObject is the inner "error" object, probed before decoding into a concrete type
+type upstreamErrorObject struct {
+	Type    string       `json:"type"`
+	Status  string       `json:"status"`
+	Message string       `json:"message"`
+	Code    UpstreamCode `json:"code"`
+}
+
+// isEmpty reports whether the object carries nothing that identifies an error
+func (o upstreamErrorObject) isEmpty() bool {
+	return o.Type == "" && o.Status == "" && o.Message == "" && o.Code.IsEmpty()
+}
+
+// ParseUpstreamError builds a NewAPIError from a failed upstream HTTP response, detecting
+// the body format so the relay adaptor does not have to guess the constructor. Bodies that
+// cannot be parsed (HTML error pages, plain text, empty) are reported as upstream errors with
+// the error code inferred from the HTTP status.
+func ParseUpstreamError(statusCode int, header http.Header, body []byte, ops ...NewAPIErrorOptions) *NewAPIError {
+	ops = append([]NewAPIErrorOptions{ErrOptionWithRetryHeaders(header)}, ops...)
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return withUpstreamError(http.StatusText(statusCode), statusCode, ErrorCodeEmptyResponse, ops...)
+	}
+	if e := parseUpstreamErrorJSON(statusCode, trimmed, false, ops...); e != nil {
+		return e
+	}
+	if isHTMLBody(header, trimmed) {
+		return withUpstreamError(htmlErrorMessage(statusCode, header, trimmed), statusCode, ErrorCodeBadResponseBody, ops...)
+	}
+	return withUpstreamError(truncateUpstreamMessage(string(trimmed)), statusCode, ErrorCodeBadResponseBody, ops...)
+}
+
+// parseUpstreamErrorJSON returns nil if the body is not a recognized JSON error envelope.
+// In strict mode, used for bodies of successful responses, only explicit error envelopes
+// are recognized: a bare "message" or "detail" field is not treated as an error.
+func parseUpstreamErrorJSON(statusCode int, body []byte, strict bool, ops ...NewAPIErrorOptions) *NewAPIError {
+	// Gemini streaming endpoints wrap the envelope in an array: [{"error": {...}}]
+	if body[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(body, &items); err != nil || len(items) == 0 {
+			return nil
+		}
+		body = bytes.TrimSpace(items[0])
+		if len(body) == 0 || body[0] != '{' {
+			return nil
+		}
+	}
+	if body[0] != '{' {
+		return nil
+	}
+	var envelope upstreamErrorEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+
+	switch {
+	case envelope.Type == "error" && len(envelope.Error) == 0 && envelope.Message != "":
+		// Responses API error event, with the error fields at the top level
+		return WithOpenAIError(OpenAIError{Message: envelope.Message, Code: envelope.Code}, statusCode, ops...)
+	case envelope.Type == "response.failed" && envelope.Response != nil && envelope.Response.Error != nil:
+		object := envelope.Response.Error
+		return WithOpenAIError(OpenAIError{Message: object.Message, Type: object.Type, Code: object.Code}, statusCode, ops...)
+	}
+
+	if len(envelope.Error) > 0 && envelope.Error[0] == '{' {
+		var object upstreamErrorObject
+		if err := json.Unmarshal(envelope.Error, &object); err != nil {
+			return nil
+		}
+		if strict && object.isEmpty() {
+			return nil
+		}
+		if envelope.Type == "error" {
+			var claudeError ClaudeError
+			if err := json.Unmarshal(envelope.Error, &claudeError); err == nil {
+				return WithClaudeError(claudeError, statusCode, ops...)
+			}
+		}
+		if object.Status != "" && object.Type == "" {
+			var geminiError GeminiError
+			if err := json.Unmarshal(envelope.Error, &geminiError); err == nil {
+				return WithGeminiError(geminiError, statusCode, ops...)
+			}
+		}
+		var openAIError OpenAIError
+		if err := json.Unmarshal(envelope.Error, &openAIError); err != nil {
+			return nil
+		}
+		return WithOpenAIError(openAIError, statusCode, ops...)
+	}
+
+	var message string
+	if len(envelope.Error) > 0 && json.Unmarshal(envelope.Error, &message) == nil && message != "" {
+		return WithOpenAIError(OpenAIError{Message: message, Type: envelope.Type, Code: envelope.Code}, statusCode, ops...)
+	}
+	if envelope.Description != nil && envelope.Code.Kind() == UpstreamCodeInt {
+		if code, ok := envelope.Code.Int(); ok {
+			if strict && midjourneyAccepted(int(code)) {
+				return nil
+			}
+			midjourneyError := MidjourneyError{Code: int(code), Description: *envelope.Description}
+			return WithMidjourneyError(midjourneyError, statusCode, ops...)
+		}
+	}
+	if strict {
+		return nil
+	}
+	message = envelope.Message
+	if message == "" && len(envelope.Detail) > 0 {
+		message = rerankDetailMessage(envelope.Detail)
+	}
+	if message == "" {
+		return nil
+	}
+	return WithOpenAIError(OpenAIError{Message: message, Type: envelope.Type, Code: envelope.Code}, statusCode, ops...)
+}
+
+// isHTMLBody reports whether the body is an HTML page, such as an nginx or Cloudflare error page
+func isHTMLBody(header http.Header, body []byte) bool {
+	if strings.HasPrefix(strings.ToLower(header.Get("Content-Type")), "text/html") {
+		return true
+	}
+	if len(body) > 64 {
+		body = body[:64]
+	}
+	prefix := strings.ToLower(string(body))
+	return strings.HasPrefix(prefix, "<!doctype html") || strings.HasPrefix(prefix, "<html")
+}
+
+// isCloudflareResponse reports whether the response was produced by the Cloudflare edge
+func isCloudflareResponse(header http.Header) bool {
+	return header.Get("Cf-Ray") != "" || strings.EqualFold(header.Get("Server"), "cloudflare")
+}
+
+// htmlErrorMessage extracts a short message from an HTML error page, preferring its <title>
+func htmlErrorMessage(statusCode int, header http.Header, body []byte) string {
+	message := htmlTitle(body)
+	if message == "" {
+		message = http.StatusText(statusCode)
+	}
+	if isCloudflareResponse(header) {
+		message = "cloudflare: " + message
+	}
+	return truncateUpstreamMessage(message)
+}
+
+// htmlTitle returns the text of the first <title> element, empty if there is none
+func htmlTitle(body []byte) string {
+	lower := bytes.ToLower(body)
+	start := bytes.Index(lower, []byte("<title"))
+	if start < 0 {
+		return ""
+	}
+	open := bytes.IndexByte(lower[start:], '>')
+	if open < 0 {
+		return ""
+	}
+	start += open + 1
+	end := bytes.Index(lower[start:], []byte("</title>"))
+	if end < 0 {
+		return ""
+	}
+	title := html.UnescapeString(string(body[start : start+end]))
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// withUpstreamError builds an upstream error for a body with no structured error object.
+// fallback is used when the HTTP status does not identify the failure.
+func withUpstreamError(message string, statusCode int, fallback ErrorCode, ops ...NewAPIErrorOptions) *NewAPIError {
+	errorCode, ok := errorCodeFromUpstreamStatus(statusCode)
+	if !ok {
+		errorCode = fallback
+	}
+	if message == "" {
+		message = errorCode.String()
+	}
+	e := &NewAPIError{
+		RelayError: OpenAIError{
+			Message: message,
+			Type:    string(ErrorTypeUpstreamError),
+		},
+		errorType:  ErrorTypeUpstreamError,
+		StatusCode: statusCode,
+		Err:        errors.New(message),
+		errorCode:  errorCode,
+		Level:      errorCode.DefaultLevel(), // Set default level
+	}
+	for _, op := range ops {
+		op(e)
+	}
+	return e
+}
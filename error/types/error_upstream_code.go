@@ -0,0 +1,174 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// UpstreamCodeKind records which JSON shape an upstream error code arrived in
+type UpstreamCodeKind int
+
+const (
+	// UpstreamCodeAbsent means the code field was missing
+	UpstreamCodeAbsent UpstreamCodeKind = iota
+
+	// UpstreamCodeNull means the code was JSON null
+	UpstreamCodeNull
+
+	// UpstreamCodeString means the code was a JSON string, e.g. "429" or "insufficient_quota"
+	UpstreamCodeString
+
+	// UpstreamCodeInt means the code was an integer literal, e.g. 429
+	UpstreamCodeInt
+
+	// UpstreamCodeFloat means the code was a number with a fraction or exponent, e.g. 429.0
+	UpstreamCodeFloat
+
+	// UpstreamCodeOther means the code was a boolean, object or array
+	UpstreamCodeOther
+)
+
+// String returns the string representation of the kind
+func (k UpstreamCodeKind) String() string {
+	switch k {
+	case UpstreamCodeAbsent:
+		return "absent"
+	case UpstreamCodeNull:
+		return "null"
+	case UpstreamCodeString:
+		return "string"
+	case UpstreamCodeInt:
+		return "int"
+	case UpstreamCodeFloat:
+		return "float"
+	case UpstreamCodeOther:
+		return "other"
+	default:
+		return "unknown"
+	}
+}
+
+// UpstreamCode is the "code" field of an OpenAI-style error. Upstreams send null, 429, 429.0
+// or "429" for the same thing; UpstreamCode normalizes all of them to one string while
+// remembering the original shape, so it can be written back unchanged.
+type UpstreamCode struct {
+	value string
+	kind  UpstreamCodeKind
+	raw   json.RawMessage // original literal for floats and non-scalar codes
+}
+
+// NewUpstreamCode builds an UpstreamCode from a Go value as it would be decoded from JSON
+func NewUpstreamCode(v any) UpstreamCode {
+	switch c := v.(type) {
+	case nil:
+		return UpstreamCode{kind: UpstreamCodeNull}
+	case UpstreamCode:
+		return c
+	case string:
+		return UpstreamCode{value: strings.TrimSpace(c), kind: UpstreamCodeString}
+	case ErrorCode:
+		return UpstreamCode{value: c.String(), kind: UpstreamCodeString}
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return UpstreamCode{kind: UpstreamCodeNull}
+	}
+	var code UpstreamCode
+	if err := code.UnmarshalJSON(data); err != nil {
+		return UpstreamCode{kind: UpstreamCodeNull}
+	}
+	return code
+}
+
+// String returns the normalized code, empty for absent or null codes
+func (c UpstreamCode) String() string {
+	return c.value
+}
+
+// Kind returns the JSON shape the code arrived in
+func (c UpstreamCode) Kind() UpstreamCodeKind {
+	return c.kind
+}
+
+// IsEmpty reports whether the code carries no usable value
+func (c UpstreamCode) IsEmpty() bool {
+	return c.value == ""
+}
+
+// Int returns the code as an integer if it is numeric, whichever shape it arrived in
+func (c UpstreamCode) Int() (int64, bool) {
+	n, err := strconv.ParseInt(c.value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface
+func (c *UpstreamCode) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "null" {
+		*c = UpstreamCode{kind: UpstreamCodeNull}
+		return nil
+	}
+	switch data[0] {
+	case '"':
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*c = UpstreamCode{value: strings.TrimSpace(s), kind: UpstreamCodeString}
+		return nil
+	case '{', '[', 't', 'f':
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, data); err != nil {
+			return err
+		}
+		*c = UpstreamCode{value: buf.String(), kind: UpstreamCodeOther, raw: json.RawMessage(buf.Bytes())}
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return err
+	}
+	literal := number.String()
+	if !strings.ContainsAny(literal, ".eE") {
+		if n, err := strconv.ParseInt(literal, 10, 64); err == nil {
+			*c = UpstreamCode{value: strconv.FormatInt(n, 10), kind: UpstreamCodeInt}
+			return nil
+		}
+		// integer too large for int64, keep the digits as they are
+		*c = UpstreamCode{value: literal, kind: UpstreamCodeInt}
+		return nil
+	}
+	value := literal
+	if f, err := strconv.ParseFloat(literal, 64); err == nil {
+		if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+			value = strconv.FormatInt(int64(f), 10)
+		} else {
+			value = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	*c = UpstreamCode{value: value, kind: UpstreamCodeFloat, raw: json.RawMessage(literal)}
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler interface, writing the code back in its original shape
+func (c UpstreamCode) MarshalJSON() ([]byte, error) {
+	switch c.kind {
+	case UpstreamCodeString:
+		return json.Marshal(c.value)
+	case UpstreamCodeInt:
+		return []byte(c.value), nil
+	case UpstreamCodeFloat, UpstreamCodeOther:
+		if len(c.raw) > 0 {
+			return c.raw, nil
+		}
+		return json.Marshal(c.value)
+	default:
+		return []byte("null"), nil
+	}
+}
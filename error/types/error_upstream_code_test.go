@@ -0,0 +1,129 @@
+package types
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+// TestUpstreamCodeUnmarshal verifies every JSON shape of an upstream code is normalized
+func TestUpstreamCodeUnmarshal(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		expectedCode string
+		expectedKind UpstreamCodeKind
+	}{
+		{"Missing", `{"message":"x"}`, "", UpstreamCodeAbsent},
+		{"Null", `{"code":null}`, "", UpstreamCodeNull},
+		{"Int", `{"code":429}`, "429", UpstreamCodeInt},
+		{"Float", `{"code":429.0}`, "429", UpstreamCodeFloat},
+		{"Exponent", `{"code":4.29e2}`, "429", UpstreamCodeFloat},
+		{"Fraction", `{"code":1.5}`, "1.5", UpstreamCodeFloat},
+		{"Numeric string", `{"code":"429"}`, "429", UpstreamCodeString},
+		{"Padded string", `{"code":" rate_limit_exceeded "}`, "rate_limit_exceeded", UpstreamCodeString},
+		{"Bool", `{"code":true}`, "true", UpstreamCodeOther},
+		{"Object", `{"code":{"a": 1}}`, `{"a":1}`, UpstreamCodeOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var openAIError OpenAIError
+			if err := json.Unmarshal([]byte(tt.body), &openAIError); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", tt.body, err)
+			}
+			if openAIError.Code.String() != tt.expectedCode {
+				t.Errorf("UpstreamCode.String() = %q, want %q", openAIError.Code.String(), tt.expectedCode)
+			}
+			if openAIError.Code.Kind() != tt.expectedKind {
+				t.Errorf("UpstreamCode.Kind() = %s, want %s", openAIError.Code.Kind(), tt.expectedKind)
+			}
+		})
+	}
+}
+
+// TestUpstreamCodeShapesAgree verifies 429, 429.0 and "429" classify the same way
+func TestUpstreamCodeShapesAgree(t *testing.T) {
+	for _, body := range []string{`{"code":429}`, `{"code":429.0}`, `{"code":"429"}`} {
+		var openAIError OpenAIError
+		if err := json.Unmarshal([]byte(body), &openAIError); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", body, err)
+		}
+		e := WithOpenAIError(openAIError, http.StatusTooManyRequests)
+		if e.GetUpstreamCode() != "429" {
+			t.Errorf("WithOpenAIError(%s) upstreamCode = %q, want %q", body, e.GetUpstreamCode(), "429")
+		}
+		if e.GetErrorCode() != ErrorCodeRateLimitExceeded {
+			t.Errorf("WithOpenAIError(%s) errorCode = %d, want %d", body, e.GetErrorCode(), ErrorCodeRateLimitExceeded)
+		}
+	}
+}
+
+// TestUpstreamCodePassthroughShape verifies a passed-through code is written back in its original shape
+func TestUpstreamCodePassthroughShape(t *testing.T) {
+	var openAIError OpenAIError
+	if err := json.Unmarshal([]byte(`{"message":"slow down","code":429}`), &openAIError); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	e := WithOpenAIError(openAIError, http.StatusTooManyRequests, ErrOptionWithUpstreamCodePassthrough())
+	data, err := json.Marshal(e.ToOpenAIError().Code)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	if string(data) != "429" {
+		t.Errorf("json.Marshal(Code) = %s, want 429", data)
+	}
+}
+
+// FuzzUpstreamCodeJSON verifies arbitrary code values survive a JSON round trip
+func FuzzUpstreamCodeJSON(f *testing.F) {
+	for _, seed := range []string{`null`, `429`, `429.0`, `"429"`, `true`, `{}`, `[]`, `1e400`, `-0`, `99999999999999999999`, `"\u0000"`} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, raw string) {
+		var code UpstreamCode
+		if err := json.Unmarshal([]byte(raw), &code); err != nil {
+			return
+		}
+		if code.String() == "<nil>" {
+			t.Fatalf("UpstreamCode(%s).String() = %q", raw, code.String())
+		}
+		data, err := json.Marshal(code)
+		if err != nil {
+			t.Fatalf("json.Marshal(UpstreamCode(%s)) error = %v", raw, err)
+		}
+		var again UpstreamCode
+		if err := json.Unmarshal(data, &again); err != nil {
+			t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+		}
+		if again.Kind() != code.Kind() || again.String() != code.String() {
+			t.Fatalf("round trip %s -> %s changed code from %q (%s) to %q (%s)", raw, data, code.String(), code.Kind(), again.String(), again.Kind())
+		}
+	})
+}
+
+// FuzzWithOpenAIError verifies arbitrary upstream OpenAI error bodies always yield a usable error
+func FuzzWithOpenAIError(f *testing.F) {
+	for _, seed := range []string{`null`, `429`, `429.0`, `"429"`, `true`, `{}`, `[]`, `1e400`} {
+		f.Add(`{"message":"upstream failure","type":"server_error","code":`+seed+`}`, http.StatusTooManyRequests)
+	}
+	f.Fuzz(func(t *testing.T, body string, statusCode int) {
+		var openAIError OpenAIError
+		if err := json.Unmarshal([]byte(body), &openAIError); err != nil {
+			return
+		}
+		e := WithOpenAIError(openAIError, statusCode)
+		if e.GetUpstreamCode() == "<nil>" {
+			t.Fatalf("WithOpenAIError(%s) upstreamCode = %q", body, e.GetUpstreamCode())
+		}
+		if !e.GetErrorCode().IsValid() {
+			t.Fatalf("WithOpenAIError(%s) errorCode = %d is not registered", body, e.GetErrorCode())
+		}
+		if result := e.ToOpenAIError(); result.Code.IsEmpty() {
+			t.Fatalf("ToOpenAIError() Code is empty for %s", body)
+		}
+		if result := e.ToClaudeError(); !claudeOfficialErrorTypes[result.Type] {
+			t.Fatalf("ToClaudeError() Type = %q is not an official Anthropic type", result.Type)
+		}
+	})
+}
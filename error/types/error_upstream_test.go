@@ -0,0 +1,157 @@
+package types
+
+import (
+	"net/http"
+	"testing"
+)
+
+// TestParseUpstreamError verifies each upstream body format is detected and typed correctly
+func TestParseUpstreamError(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCode       int
+		header           http.Header
+		body             string
+		expectedType     ErrorType
+		expectedCode     ErrorCode
+		expectedUpstream string
+		expectedMessage  string
+	}{
+		{
+			name:             "OpenAI",
+			statusCode:       http.StatusTooManyRequests,
+			body:             `{"error":{"message":"Rate limit reached","type":"requests","param":null,"code":"rate_limit_exceeded"}}`,
+			expectedType:     ErrorTypeOpenAIError,
+			expectedCode:     ErrorCodeRateLimitExceeded,
+			expectedUpstream: "rate_limit_exceeded",
+			expectedMessage:  "Rate limit reached",
+		},
+		{
+			name:            "OpenAI string error",
+			statusCode:      http.StatusUnauthorized,
+			body:            `{"error":"invalid api key"}`,
+			expectedType:    ErrorTypeOpenAIError,
+			expectedCode:    ErrorCodeChannelInvalidKey,
+			expectedMessage: "invalid api key",
+		},
+		{
+			name:             "Claude",
+			statusCode:       StatusUpstreamOverloaded,
+			body:             `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`,
+			expectedType:     ErrorTypeClaudeError,
+			expectedCode:     ErrorCodeUpstreamOverloaded,
+			expectedUpstream: "overloaded_error",
+			expectedMessage:  "Overloaded",
+		},
+		{
+			name:             "Gemini",
+			statusCode:       http.StatusTooManyRequests,
+			body:             `{"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}`,
+			expectedType:     ErrorTypeGeminiError,
+			expectedCode:     ErrorCodeRateLimitExceeded,
+			expectedUpstream: GeminiStatusResourceExhausted,
+			expectedMessage:  "Resource has been exhausted",
+		},
+		{
+			name:             "Gemini stream array",
+			statusCode:       http.StatusBadRequest,
+			body:             `[{"error":{"code":400,"message":"API key not valid","status":"INVALID_ARGUMENT"}}]`,
+			expectedType:     ErrorTypeGeminiError,
+			expectedCode:     ErrorCodeInvalidRequest,
+			expectedUpstream: GeminiStatusInvalidArgument,
+			expectedMessage:  "API key not valid",
+		},
+		{
+			name:             "Midjourney",
+			statusCode:       http.StatusOK,
+			body:             `{"code":24,"description":"banned prompt","result":""}`,
+			expectedType:     ErrorTypeMidjourneyError,
+			expectedCode:     ErrorCodePromptBlocked,
+			expectedUpstream: "24",
+			expectedMessage:  "banned prompt",
+		},
+		{
+			name:            "Generic detail",
+			statusCode:      http.StatusUnprocessableEntity,
+			body:            `{"detail":[{"msg":"field required"}]}`,
+			expectedType:    ErrorTypeOpenAIError,
+			expectedCode:    ErrorCodeInvalidRequest,
+			expectedMessage: "field required",
+		},
+		{
+			name:            "nginx HTML",
+			statusCode:      http.StatusBadGateway,
+			header:          http.Header{"Content-Type": {"text/html"}},
+			body:            "<html>\r\n<head><title>502 Bad Gateway</title></head>\r\n<body><center><h1>502 Bad Gateway</h1></center></body></html>",
+			expectedType:    ErrorTypeUpstreamError,
+			expectedCode:    ErrorCodeBadResponse,
+			expectedMessage: "502 Bad Gateway",
+		},
+		{
+			name:            "Cloudflare HTML",
+			statusCode:      522,
+			header:          http.Header{"Server": {"cloudflare"}, "Cf-Ray": {"8a1b2c3d4e5f-LAX"}},
+			body:            `<!DOCTYPE html><html><head><title>api.example.com | 522: Connection timed out</title></head></html>`,
+			expectedType:    ErrorTypeUpstreamError,
+			expectedCode:    ErrorCodeBadResponseStatusCode,
+			expectedMessage: "cloudflare: api.example.com | 522: Connection timed out",
+		},
+		{
+			name:            "Plain text",
+			statusCode:      http.StatusServiceUnavailable,
+			body:            "upstream connect error or disconnect/reset before headers\n",
+			expectedType:    ErrorTypeUpstreamError,
+			expectedCode:    ErrorCodeServiceUnavailable,
+			expectedMessage: "upstream connect error or disconnect/reset before headers",
+		},
+		{
+			name:            "Unrecognized JSON",
+			statusCode:      http.StatusInternalServerError,
+			body:            `{"ok":false}`,
+			expectedType:    ErrorTypeUpstreamError,
+			expectedCode:    ErrorCodeBadResponse,
+			expectedMessage: `{"ok":false}`,
+		},
+		{
+			name:            "Empty body",
+			statusCode:      http.StatusGatewayTimeout,
+			body:            "  ",
+			expectedType:    ErrorTypeUpstreamError,
+			expectedCode:    ErrorCodeChannelResponseTimeExceeded,
+			expectedMessage: "Gateway Timeout",
+		},
+		{
+			name:            "Empty body without error status",
+			statusCode:      http.StatusOK,
+			body:            "",
+			expectedType:    ErrorTypeUpstreamError,
+			expectedCode:    ErrorCodeEmptyResponse,
+			expectedMessage: "OK",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := tt.header
+			if header == nil {
+				header = http.Header{}
+			}
+			e := ParseUpstreamError(tt.statusCode, header, []byte(tt.body))
+			if e.GetErrorType() != tt.expectedType {
+				t.Errorf("ParseUpstreamError() errorType = %q, want %q", e.GetErrorType(), tt.expectedType)
+			}
+			if e.GetErrorCode() != tt.expectedCode {
+				t.Errorf("ParseUpstreamError() errorCode = %d, want %d", e.GetErrorCode(), tt.expectedCode)
+			}
+			if e.GetUpstreamCode() != tt.expectedUpstream {
+				t.Errorf("ParseUpstreamError() upstreamCode = %q, want %q", e.GetUpstreamCode(), tt.expectedUpstream)
+			}
+			if e.Error() != tt.expectedMessage {
+				t.Errorf("ParseUpstreamError() message = %q, want %q", e.Error(), tt.expectedMessage)
+			}
+			if e.RelayError == nil {
+				t.Errorf("ParseUpstreamError() RelayError is nil")
+			}
+		})
+	}
+}
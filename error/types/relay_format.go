@@ -0,0 +1,18 @@
+package types
+
+// RelayFormat is the API dialect a client speaks, which decides the shape of its error responses
+type RelayFormat string
+
+const (
+	RelayFormatOpenAI          RelayFormat = "openai"
+	RelayFormatClaude          RelayFormat = "claude"
+	RelayFormatGemini          RelayFormat = "gemini"
+	RelayFormatOpenAIResponses RelayFormat = "openai_responses"
+	RelayFormatOpenAIAudio     RelayFormat = "openai_audio"
+	RelayFormatOpenAIImage     RelayFormat = "openai_image"
+	RelayFormatOpenAIRealtime  RelayFormat = "openai_realtime"
+	RelayFormatRerank          RelayFormat = "rerank"
+	RelayFormatEmbedding       RelayFormat = "embedding"
+	RelayFormatTask            RelayFormat = "task"
+	RelayFormatMjProxy         RelayFormat = "mj_proxy"
+)